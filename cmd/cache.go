@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cached data",
+	Long:  `Namespace lists are cached under $XDG_CACHE_HOME/grafana-connect to keep pickers and completion fast.`,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached data",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		n, err := cache.Clear()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🧹 Cleared %d cached entries from %s\n", n, cache.Dir())
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
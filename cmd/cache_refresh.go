@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/cache"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagRefreshKind string
+	flagRefreshKey  string
+)
+
+// cacheRefreshCmd is spawned detached to refresh a stale cache entry.
+var cacheRefreshCmd = &cobra.Command{
+	Use:    cache.RefreshCommand,
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The lock has to go before exiting, which skips deferred calls
+		code := refreshEntry(flagRefreshKind, flagRefreshKey)
+		cache.Unlock(flagRefreshKind, flagRefreshKey)
+		os.Exit(code)
+	},
+}
+
+// refreshEntry fetches the entry again and returns the exit code. Nobody is watching,
+// so failures are silent and the stale entry stays.
+func refreshEntry(kind, key string) int {
+	switch kind {
+	case cache.Namespaces:
+		state, err := kube.GetCurrentState() // the context comes from --context
+		if err != nil {
+			return 1
+		}
+		namespaces, source, err := kube.ClusterNamespaces(state)
+		if err != nil {
+			return 1
+		}
+		if err := cache.Store(cache.Namespaces, key, source, namespaces); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+func init() {
+	cacheRefreshCmd.Flags().StringVar(&flagRefreshKind, "kind", "", "Kind of entry")
+	cacheRefreshCmd.Flags().StringVar(&flagRefreshKey, "key", "", "Entry to refresh")
+	rootCmd.AddCommand(cacheRefreshCmd)
+}
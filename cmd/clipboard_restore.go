@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/clipboard"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagRestoreAfter   time.Duration
+	flagRestoreBackend string
+)
+
+// clipboardRestoreCmd is spawned detached by the launcher to clear a copied password.
+var clipboardRestoreCmd = &cobra.Command{
+	Use:    clipboard.RestoreCommand,
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Nobody is watching; failures are silent
+		if err := clipboard.RunRestore(os.Stdin, flagRestoreAfter, flagRestoreBackend); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	clipboardRestoreCmd.Flags().DurationVar(&flagRestoreAfter, "after", 30*time.Second, "Delay before restoring")
+	clipboardRestoreCmd.Flags().StringVar(&flagRestoreBackend, "backend", "", "Clipboard backend to use")
+	rootCmd.AddCommand(clipboardRestoreCmd)
+}
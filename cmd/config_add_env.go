@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configAddEnvCmd = &cobra.Command{
+	Use:   "add-env <name> --base-url <url>",
+	Short: "Add an environment",
+	Long: `Adds an environment without the wizard, one flag per field:
+
+  grafana-connect config add-env prod --base-url https://grafana.example.com \
+    --alias p --context-match 'prod-cluster' --password-ref keyring:grafana-connect/prod`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		doc, before := openConfigDocument()
+		i, err := doc.AddEnv(args[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		applyEnvFieldFlags(cmd, doc, i, args[0])
+		saveConfigDocument(doc, before)
+		fmt.Printf("✅ Added %s to %s\n", doc.EnvName(args[0]), doc.Path)
+	},
+}
+
+func init() {
+	addEnvFieldFlags(configAddEnvCmd)
+	_ = configAddEnvCmd.MarkFlagRequired(envFieldFlag("base_url"))
+	configCmd.AddCommand(configAddEnvCmd)
+}
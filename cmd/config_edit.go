@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Shared by the scriptable config commands (set, add-env, edit-env, remove-env).
+
+// openConfigDocument opens the config file LoadConfig reads, or the default path
+// for a new one. It also returns the problems the file already had, so saving only
+// refuses edits that make things worse.
+func openConfigDocument() (*config.Document, map[string]bool) {
+	path, err := config.FindConfigFile()
+	if err != nil {
+		path = config.DefaultConfigPath()
+	}
+	doc, err := config.OpenDocument(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return doc, problemSet(doc)
+}
+
+// saveConfigDocument writes doc back, unless the edit introduced validation problems.
+func saveConfigDocument(doc *config.Document, before map[string]bool) {
+	failed := false
+	for msg := range problemSet(doc) {
+		if !before[msg] {
+			fmt.Printf("❌ %s\n", msg)
+			failed = true
+		}
+	}
+	if failed {
+		fmt.Println("   Config not saved.")
+		os.Exit(1)
+	}
+	if err := doc.Save(); err != nil {
+		fmt.Printf("❌ Save Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func problemSet(doc *config.Document) map[string]bool {
+	set := map[string]bool{}
+	data, err := doc.Bytes()
+	if err != nil {
+		return set
+	}
+	problems, _ := config.Validate(data)
+	for _, p := range problems {
+		set[p.Message] = true
+	}
+	return set
+}
+
+// envFieldFlag is the flag for an environment field: base_url -> base-url, match.server -> match-server.
+func envFieldFlag(field string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(field)
+}
+
+// addEnvFieldFlags adds one flag per scalar field of config.Environment.
+func addEnvFieldFlags(c *cobra.Command) {
+	for _, field := range config.EnvFields() {
+		c.Flags().String(envFieldFlag(field), "", "Set "+field+" (empty to remove)")
+	}
+	_ = c.RegisterFlagCompletionFunc("match-mode", cobra.FixedCompletions(
+		[]string{config.MatchModeAll, config.MatchModeAny}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// applyEnvFieldFlags sets the fields given on the command line on environment #i.
+// It goes by index, so names containing dots work, and sets the name last.
+func applyEnvFieldFlags(cmd *cobra.Command, doc *config.Document, i int, name string) {
+	rename := ""
+	for _, field := range config.EnvFields() {
+		flag := cmd.Flags().Lookup(envFieldFlag(field))
+		if !flag.Changed {
+			continue
+		}
+		if field == "name" {
+			rename = flag.Value.String()
+			continue
+		}
+		if err := doc.SetEnv(i, field, flag.Value.String()); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if rename != "" && rename != name {
+		if doc.HasEnv(rename) {
+			fmt.Printf("❌ environment %q already exists\n", rename)
+			os.Exit(1)
+		}
+		if err := doc.SetEnv(i, "name", rename); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configEditEnvCmd = &cobra.Command{
+	Use:   "edit-env <name|alias>",
+	Short: "Change fields of an environment",
+	Long: `Changes the given fields of an environment, found by name or alias.
+Use --name to rename it, and an empty value to remove a field:
+
+  grafana-connect config edit-env prod --dashboard abc123/pods --priority 10
+  grafana-connect config edit-env prod --name production --password ''`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeEnv,
+	Run: func(cmd *cobra.Command, args []string) {
+		doc, before := openConfigDocument()
+		i := doc.EnvIndex(args[0])
+		if i < 0 {
+			fmt.Printf("❌ No environment named %q\n", args[0])
+			os.Exit(1)
+		}
+		applyEnvFieldFlags(cmd, doc, i, doc.EnvName(args[0]))
+		saveConfigDocument(doc, before)
+		fmt.Printf("✅ Config saved to: %s\n", doc.Path)
+	},
+}
+
+func init() {
+	addEnvFieldFlags(configEditEnvCmd)
+	configCmd.AddCommand(configEditEnvCmd)
+}
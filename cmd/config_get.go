@@ -4,14 +4,18 @@ import (
 	"fmt"
 
 	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/secret"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
+var flagEffective bool // --effective
+
 var configGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Display current configuration",
-	Long:  "Prints the current configuration YAML to stdout. Passwords are masked for security.",
+	Long: `Prints the current configuration YAML to stdout. Passwords and tokens are masked for security and password_ref/token_ref entries are checked.
+With --effective every environment is shown with the defaults applied, as used at launch.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load the config struct (which handles finding the file)
 		cfg, err := config.LoadConfig()
@@ -25,11 +29,34 @@ var configGetCmd = &cobra.Command{
 		safeCfg := *cfg
 		maskedEnvs := make([]config.Environment, len(cfg.Environments))
 		copy(maskedEnvs, cfg.Environments)
+		if flagEffective {
+			for i := range maskedEnvs {
+				maskedEnvs[i] = cfg.Effective(&cfg.Environments[i])
+			}
+			// The top-level clipboard and default_dashboard keys are folded into the defaults
+			safeCfg.Defaults = cfg.EffectiveDefaults()
+			safeCfg.Clipboard, safeCfg.ClipboardClearAfter, safeCfg.DefaultDashboard = "", "", ""
+		}
 
+		var warnings []string
 		for i := range maskedEnvs {
+			// Refs are shown as-is, but check they actually resolve
+			if maskedEnvs[i].PasswordRef != "" {
+				if _, err := secret.Password(maskedEnvs[i]); err != nil {
+					warnings = append(warnings, fmt.Sprintf("password_ref for %s: %v", maskedEnvs[i].Name, err))
+				}
+			}
+			if maskedEnvs[i].TokenRef != "" {
+				if _, err := secret.Token(maskedEnvs[i]); err != nil {
+					warnings = append(warnings, fmt.Sprintf("token_ref for %s: %v", maskedEnvs[i].Name, err))
+				}
+			}
 			if maskedEnvs[i].Password != "" {
 				maskedEnvs[i].Password = "*****"
 			}
+			if maskedEnvs[i].Token != "" {
+				maskedEnvs[i].Token = "*****"
+			}
 		}
 		safeCfg.Environments = maskedEnvs
 
@@ -40,11 +67,20 @@ var configGetCmd = &cobra.Command{
 			return
 		}
 
-		fmt.Println("# Current Configuration (Passwords Masked)")
+		if flagEffective {
+			fmt.Println("# Effective Configuration (Secrets Masked)")
+		} else {
+			fmt.Println("# Current Configuration (Secrets Masked)")
+		}
 		fmt.Println(string(data))
+
+		for _, w := range warnings {
+			fmt.Printf("⚠️  Unresolved %s\n", w)
+		}
 	},
 }
 
 func init() {
+	configGetCmd.Flags().BoolVar(&flagEffective, "effective", false, "Show environments with the defaults applied")
 	configCmd.AddCommand(configGetCmd)
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configRemoveEnvCmd = &cobra.Command{
+	Use:               "remove-env <name|alias>",
+	Short:             "Remove an environment",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeEnv,
+	Run: func(cmd *cobra.Command, args []string) {
+		doc, before := openConfigDocument()
+		name := doc.EnvName(args[0])
+		if err := doc.RemoveEnv(args[0]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		saveConfigDocument(doc, before)
+		fmt.Printf("🗑️  Removed %s from %s\n", name, doc.Path)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configRemoveEnvCmd)
+}
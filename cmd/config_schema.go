@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long: `Prints a JSON Schema describing config.yaml, for editor completion and validation.
+Save it and point your editor at it, e.g. with the YAML language server:
+
+  grafana-connect config schema > ~/.config/grafana-connect/config.schema.json
+  # yaml-language-server: $schema=./config.schema.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(config.Schema()); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
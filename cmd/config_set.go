@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <path>=<value>...",
+	Short: "Set configuration values by path",
+	Long: `Sets values in the config file without the wizard. Paths are dotted YAML keys;
+environments are addressed by name or alias. Comments and key order are kept.
+An empty value removes the key; list values are comma separated.
+
+  grafana-connect config set clipboard=osc52
+  grafana-connect config set environments.prod.dashboard=abc123/pods
+  grafana-connect config set dashboards.pods.org_id=2
+  grafana-connect config set environments.prod.password=`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		doc, before := openConfigDocument()
+		for _, arg := range args {
+			path, value, ok := strings.Cut(arg, "=")
+			if !ok || path == "" {
+				fmt.Printf("❌ %q is not <path>=<value>\n", arg)
+				os.Exit(1)
+			}
+			if err := doc.Set(path, value); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		}
+		saveConfigDocument(doc, before)
+		fmt.Printf("✅ Config saved to: %s\n", doc.Path)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+}
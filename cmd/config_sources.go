@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configSourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "Show the files the configuration is read from",
+	Long:  "Lists the config file and the files it includes, then which file each environment comes from.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("❌ Could not load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("📂 Files")
+		for i, file := range cfg.Files {
+			if i == 0 {
+				fmt.Printf("   %s\n", shortPath(file))
+			} else {
+				fmt.Printf("   %s (included)\n", shortPath(file))
+			}
+		}
+
+		fmt.Println("\n🌍 Environments")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, env := range cfg.Environments {
+			src := cfg.Sources[env.Name]
+			from := shortPath(src.File)
+			if src.Overrides != "" {
+				from += " (overrides " + shortPath(src.Overrides) + ")"
+			}
+			fmt.Fprintf(w, "   %s\t%s\n", env.Name, from)
+		}
+		w.Flush()
+	},
+}
+
+// shortPath abbreviates the home directory to ~.
+func shortPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+func init() {
+	configCmd.AddCommand(configSourcesCmd)
+}
@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/grafana"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/secret"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/ui"
+	"github.com/ktr0731/go-fuzzyfinder"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var configUpdateCmd = &cobra.Command{
@@ -18,18 +23,22 @@ var configUpdateCmd = &cobra.Command{
 	Long:  "Starts a wizard to add new environments or update existing ones based on the Grafana Base URL.",
 	Run: func(cmd *cobra.Command, args []string) {
 		// 1. Setup Paths
-		home, _ := os.UserHomeDir()
-		configDir := filepath.Join(home, ".config", "grafana-connect")
-		configPath := filepath.Join(configDir, "config.yaml")
+		configPath := config.DefaultConfigPath()
 
-		// 2. Load Existing or Create New
-		var cfg config.Config
+		// 2. Load Existing or Create New. Edits go through the YAML node tree so
+		// comments and keys this wizard doesn't know about are kept.
 		if _, err := os.Stat(configPath); err == nil {
 			fmt.Printf("📂 Loading config from: %s\n", configPath)
-			data, err := os.ReadFile(configPath)
-			if err == nil {
-				_ = yaml.Unmarshal(data, &cfg)
-			}
+		}
+		doc, err := config.OpenDocument(configPath)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		cfg, err := doc.Decode()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
 		}
 
 		fmt.Println("🧙 Starting setup wizard...")
@@ -88,49 +97,59 @@ var configUpdateCmd = &cobra.Command{
 			}
 			ctxMatch, _ := pCtx.Run()
 
-			// NEW: Dashboard Path per env
-			pDash := promptui.Prompt{
-				Label:   "Dashboard Path (Slug)",
-				Default: defDash,
-			}
-			if defDash == "" {
-				pDash.Default = "k8s-pod-resources-clean/kubernetes-pod-resource-dashboard-v3"
-			}
-			dashboard, _ := pDash.Run()
-
-			pUID := promptui.Prompt{Label: "Prometheus UID", Default: defUID}
-			uid, _ := pUID.Run()
-
 			pUser := promptui.Prompt{Label: "Username", Default: defUser}
 			user, _ := pUser.Run()
 
 			pPass := promptui.Prompt{Label: "Password (leave empty to keep)", Mask: '*'}
 			pass, _ := pPass.Run()
 
+			passRef := ""
 			if existingEnv != nil && pass == "" {
 				pass = existingEnv.Password
+				passRef = existingEnv.PasswordRef
+			} else if pass != "" && secret.KeyringAvailable() {
+				// Offer to keep new passwords out of the YAML
+				pKeyring := promptui.Prompt{Label: "Store password in OS keyring", IsConfirm: true, Default: "y"}
+				if _, err := pKeyring.Run(); err == nil {
+					if err := secret.StoreKeyring(name, pass); err != nil {
+						fmt.Printf("⚠️  %v (saving to config instead)\n", err)
+					} else {
+						pass, passRef = "", secret.KeyringRef(name)
+						fmt.Println("🔐 Password stored in keyring.")
+					}
+				}
 			}
 
-			newEnv := config.Environment{
-				Name: name, Alias: alias, ContextMatch: ctxMatch, BaseURL: baseURL,
-				Dashboard: dashboard, PrometheusUID: uid, Username: user, Password: pass,
+			// Fields the wizard doesn't ask about (match, dashboards, ...) stay as they are
+			var newEnv config.Environment
+			if existingEnv != nil {
+				newEnv = *existingEnv
 			}
+			newEnv.Name, newEnv.Alias, newEnv.ContextMatch, newEnv.BaseURL = name, alias, ctxMatch, baseURL
+			newEnv.Username, newEnv.Password, newEnv.PasswordRef = user, pass, passRef
 
-			if idx >= 0 {
-				cfg.Environments[idx] = newEnv
-			} else {
+			newEnv.Dashboard = pickDashboardPath(newEnv, defDash)
+
+			newEnv.PrometheusUID = pickPrometheusUID(newEnv, defUID)
+
+			if idx < 0 {
+				if idx, err = doc.AddEnv(name); err != nil {
+					fmt.Printf("❌ %v\n", err)
+					continue
+				}
 				cfg.Environments = append(cfg.Environments, newEnv)
+			} else {
+				cfg.Environments[idx] = newEnv
+			}
+			if err := writeWizardFields(doc, idx, newEnv); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
 			}
 			fmt.Println("✅ Saved environment.")
 		}
 
-		// 5. Write to disk
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			fmt.Printf("❌ FS Error: %v\n", err)
-			return
-		}
-		data, _ := yaml.Marshal(&cfg)
-		if err := os.WriteFile(configPath, data, 0600); err != nil {
+		// 5. Write to disk (atomically, keeping a .bak of the previous version)
+		if err := doc.Save(); err != nil {
 			fmt.Printf("❌ Save Error: %v\n", err)
 			return
 		}
@@ -139,6 +158,80 @@ var configUpdateCmd = &cobra.Command{
 	},
 }
 
+// writeWizardFields copies the fields the wizard prompts for into the i-th environment
+// of doc. Empty values remove the key.
+func writeWizardFields(doc *config.Document, i int, env config.Environment) error {
+	for _, f := range []struct{ field, value string }{
+		{"name", env.Name},
+		{"alias", env.Alias},
+		{"context_match", env.ContextMatch},
+		{"base_url", env.BaseURL},
+		{"dashboard", env.Dashboard},
+		{"prometheus_uid", env.PrometheusUID},
+		{"username", env.Username},
+		{"password", env.Password},
+		{"password_ref", env.PasswordRef},
+	} {
+		if err := doc.SetEnv(i, f.field, f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pickDashboardPath offers the dashboards of the live instance, falling back to
+// a free-text slug prompt if Grafana can't be queried or the user skips the picker.
+func pickDashboardPath(env config.Environment, defDash string) string {
+	pBrowse := promptui.Prompt{Label: "Browse dashboards on Grafana", IsConfirm: true, Default: "y"}
+	if _, err := pBrowse.Run(); err == nil {
+		dash, err := pickDashboard(env)
+		if err == nil {
+			fmt.Printf("✅ Using dashboard %s\n", dash)
+			return dash
+		}
+		if !errors.Is(err, fuzzyfinder.ErrAbort) {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	// NEW: Dashboard Path per env. Left empty, defaults.dashboard applies.
+	pDash := promptui.Prompt{
+		Label:   "Dashboard Path (Slug, empty for the default)",
+		Default: defDash,
+	}
+	dashboard, _ := pDash.Run()
+	return dashboard
+}
+
+// pickPrometheusUID lets the user choose among the Prometheus datasources of the live
+// instance, falling back to a free-text prompt if Grafana can't be queried.
+func pickPrometheusUID(env config.Environment, defUID string) string {
+	fmt.Println("🔎 Looking up Prometheus datasources...")
+	sources, err := listPrometheusDatasources(env)
+	if err != nil {
+		fmt.Printf("⚠️  Could not list datasources: %v\n", err)
+	} else if len(sources) == 0 {
+		fmt.Println("⚠️  No Prometheus datasources found.")
+	} else if ds, err := ui.SelectDatasource("Prometheus Datasource", sources); err == nil {
+		fmt.Printf("✅ Using %s (%s)\n", ds.Name, ds.UID)
+		return ds.UID
+	}
+
+	pUID := promptui.Prompt{Label: "Prometheus UID", Default: defUID}
+	uid, _ := pUID.Run()
+	return uid
+}
+
+func listPrometheusDatasources(env config.Environment) ([]grafana.Datasource, error) {
+	client, err := grafana.ForEnv(env)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return client.DatasourcesOfType(ctx, "prometheus")
+}
+
 func init() {
 	configCmd.AddCommand(configUpdateCmd)
 }
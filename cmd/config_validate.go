@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the configuration file for mistakes",
+	Long: `Reports unknown keys (typos), missing name/base_url, invalid URLs, regexes that
+don't compile and duplicate names, aliases or base URLs, with their line and column.
+Included files are checked too, along with conflicts between them.
+Checks the default config file unless one is given. Exits non-zero on problems.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		} else {
+			var err error
+			if path, err = config.FindConfigFile(); err != nil {
+				fmt.Printf("❌ Could not load config: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		err := config.ValidateConfig(path)
+		var invalid *config.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			for _, p := range invalid.Problems {
+				fmt.Printf("❌ %s\n", p)
+			}
+			fmt.Printf("\n%d problem(s) found.\n", len(invalid.Problems))
+			os.Exit(1)
+		case err != nil:
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s is valid.\n", path)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/grafana"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/ui"
+	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/spf13/cobra"
+)
+
+var dashboardsCmd = &cobra.Command{
+	Use:   "dashboards [query]",
+	Short: "Browse the dashboards of an environment and open one",
+	Long:  "Searches Grafana for dashboards on the resolved environment, lets you pick one and opens it filtered to the namespace.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("❌ Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		target := resolveTarget(cfg)
+		if target == nil {
+			return
+		}
+		if err := applyTimeRange(target); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		query := ""
+		if len(args) > 0 {
+			query = args[0]
+		}
+		hit, err := searchAndPickDashboard(target.Env, query)
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		target.Dashboard = config.Dashboard{Path: hit.Path()}
+		launch(cfg, *target)
+	},
+}
+
+// pickDashboard asks Grafana for the env's dashboards and returns the "uid/slug" of the one picked.
+func pickDashboard(env config.Environment) (string, error) {
+	hit, err := searchAndPickDashboard(env, "")
+	if err != nil {
+		return "", err
+	}
+	return hit.Path(), nil
+}
+
+func searchAndPickDashboard(env config.Environment, query string) (*grafana.SearchHit, error) {
+	client, err := grafana.ForEnv(env)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fmt.Fprintf(os.Stderr, "🔎 Searching dashboards on %s...\n", env.Name)
+	hits, err := client.SearchDashboards(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("could not search dashboards: %w", err)
+	}
+	if len(hits) == 0 {
+		return nil, fmt.Errorf("no dashboards found on %s", env.BaseURL)
+	}
+	return ui.SelectDashboard(hits)
+}
+
+func init() {
+	addTargetFlags(dashboardsCmd)
+	addTimeFlags(dashboardsCmd)
+	addOutputFlags(dashboardsCmd)
+	rootCmd.AddCommand(dashboardsCmd)
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/clipboard"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/grafana"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/launcher"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/secret"
+	"github.com/spf13/cobra"
+)
+
+var flagDoctorGrafana bool // --grafana
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose config, kubeconfig, clipboard, browser and Grafana setup",
+	Long: `Runs a series of checks and prints a pass/warn/fail report.
+With --grafana it also logs in to every environment and checks its dashboards exist.
+Exits non-zero if any check fails.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		r := &report{}
+		fmt.Println("🩺 grafana-connect doctor")
+
+		cfg := r.checkConfig()
+		if cfg != nil {
+			r.checkEnvironments(cfg)
+			r.checkContexts(cfg)
+		}
+		r.checkClipboard(cfg)
+		r.checkBrowser()
+		if cfg != nil && flagDoctorGrafana {
+			r.checkGrafana(cfg)
+		}
+
+		fmt.Printf("\n%d passed, %d warnings, %d failed\n", r.passed, r.warned, r.failed)
+		if r.failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// report prints check results and keeps the tally.
+type report struct {
+	passed, warned, failed int
+}
+
+func (r *report) section(title string) { fmt.Printf("\n%s\n", title) }
+
+func (r *report) pass(format string, args ...any) {
+	r.passed++
+	fmt.Printf("  ✅ "+format+"\n", args...)
+}
+
+func (r *report) warn(format string, args ...any) {
+	r.warned++
+	fmt.Printf("  ⚠️  "+format+"\n", args...)
+}
+
+func (r *report) fail(format string, args ...any) {
+	r.failed++
+	fmt.Printf("  ❌ "+format+"\n", args...)
+}
+
+func (r *report) checkConfig() *config.Config {
+	r.section("Config")
+	config.OnWarning(func(p config.Problem) { r.warn("%s", p) })
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		r.fail("Could not load config: %v", err)
+		fmt.Println("     Run 'grafana-connect config update' to generate one.")
+		return nil
+	}
+	r.pass("Loaded %s", config.ConfigFileUsed())
+	for _, file := range cfg.Files[1:] {
+		r.pass("Included %s", file)
+	}
+	if len(cfg.Environments) == 0 {
+		r.warn("No environments defined")
+	}
+	return cfg
+}
+
+func (r *report) checkEnvironments(cfg *config.Config) {
+	r.section("Environments")
+	for i := range cfg.Environments {
+		env := &cfg.Environments[i]
+		if err := kube.CheckEnvironment(env); err != nil {
+			r.fail("%s: %v", env.Name, err)
+		} else if !kube.HasMatchers(env) {
+			r.warn("%s: no context_match or match rules, only reachable with -e / -I", env.Name)
+		} else {
+			r.pass("%s: matchers compile (%s)", env.Name, env.ContextMatch)
+		}
+
+		if env.PasswordRef != "" {
+			if _, err := secret.Password(*env); err != nil {
+				r.fail("%s: password_ref does not resolve: %v", env.Name, err)
+			}
+		}
+		if env.TokenRef != "" {
+			if _, err := secret.Token(*env); err != nil {
+				r.fail("%s: token_ref does not resolve: %v", env.Name, err)
+			}
+		}
+	}
+}
+
+// checkContexts shows which environment each local kube context maps to,
+// flags ties and environments no context maps to.
+func (r *report) checkContexts(cfg *config.Config) {
+	r.section("Kubeconfig contexts")
+	states, err := kube.ListContexts()
+	if err != nil {
+		r.warn("Could not read kubeconfig: %v", err)
+		return
+	}
+	if len(states) == 0 {
+		r.warn("No contexts in kubeconfig")
+		return
+	}
+
+	current := ""
+	if state, err := kube.GetCurrentState(); err == nil {
+		current = state.Context
+	}
+
+	matched := map[string]bool{}
+	unmatched := 0
+	for _, state := range states {
+		marker := ""
+		if state.Context == current {
+			marker = " (current)"
+		}
+
+		env, err := kube.FindMatchingEnv(state, cfg)
+		var ambiguous *kube.AmbiguousMatchError
+		switch {
+		case errors.As(err, &ambiguous):
+			r.fail("%s%s: %v", state.Context, marker, err)
+			for _, c := range ambiguous.Candidates {
+				matched[c.Env.Name] = true
+			}
+		case err != nil && state.Context == current:
+			r.warn("%s%s: no environment matches", state.Context, marker)
+		case err != nil:
+			unmatched++
+		default:
+			matched[env.Name] = true
+			r.pass("%s%s → %s", state.Context, marker, env.Name)
+		}
+	}
+	if unmatched > 0 {
+		fmt.Printf("     (%d other contexts match no environment)\n", unmatched)
+	}
+
+	for i := range cfg.Environments {
+		env := &cfg.Environments[i]
+		if kube.HasMatchers(env) && !matched[env.Name] {
+			r.warn("%s: no local context matches it", env.Name)
+		}
+	}
+}
+
+func (r *report) checkClipboard(cfg *config.Config) {
+	r.section("Clipboard")
+	name, clearAfter := "", ""
+	if cfg != nil {
+		defaults := cfg.EffectiveDefaults()
+		name, clearAfter = defaults.Clipboard, defaults.ClipboardClearAfter
+	}
+	if err := clipboard.Use(name); err != nil {
+		r.fail("%v", err)
+		return
+	}
+
+	b := clipboard.Current()
+	switch {
+	case b.Name() == clipboard.None:
+		r.warn("Clipboard disabled (none), passwords won't be copied")
+	case !b.Available():
+		r.fail("Backend %s is not available", b.Name())
+	default:
+		r.pass("Using %s", b.Name())
+	}
+
+	if clearAfter != "" {
+		if _, err := time.ParseDuration(clearAfter); err != nil {
+			r.fail("clipboard_clear_after: %v", err)
+		} else if _, ok := b.(clipboard.Reader); !ok {
+			r.warn("clipboard_clear_after is set but %s can't read the clipboard back, so it won't be cleared", b.Name())
+		}
+	}
+}
+
+func (r *report) checkBrowser() {
+	r.section("Browser")
+	if name, ok := launcher.BrowserCommand(); ok {
+		r.pass("Using %s", name)
+	} else {
+		r.warn("%s not found; use --print or --copy-url", name)
+	}
+}
+
+func (r *report) checkGrafana(cfg *config.Config) {
+	for i := range cfg.Environments {
+		env := cfg.Effective(&cfg.Environments[i])
+		r.section("Grafana: " + env.Name)
+
+		client, err := grafana.ForEnv(env)
+		if err != nil {
+			r.fail("%v", err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		version, err := client.Health(ctx)
+		if err != nil {
+			r.fail("%s unreachable: %v", env.BaseURL, err)
+			cancel()
+			continue
+		}
+		r.pass("%s reachable (Grafana %s)", env.BaseURL, version)
+
+		if org, err := client.CurrentOrg(ctx); err != nil {
+			r.fail("Login failed: %v", err)
+			cancel()
+			continue
+		} else {
+			r.pass("Logged in (org %s)", org)
+		}
+
+		dashboards := cfg.DashboardsFor(&env)
+		for _, name := range cfg.DashboardNames(&env) {
+			path := dashboards[name].Path
+			if title, err := client.DashboardTitle(ctx, path); err != nil {
+				r.fail("Dashboard %s (%s): %v", name, path, err)
+			} else {
+				r.pass("Dashboard %s: %s", name, title)
+			}
+		}
+		cancel()
+	}
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&flagDoctorGrafana, "grafana", false, "Also check Grafana reachability, login and dashboards")
+	rootCmd.AddCommand(doctorCmd)
+}
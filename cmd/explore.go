@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagQuery      string // --query
+	flagDatasource string // --datasource
+)
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore [query-name]",
+	Short: "Open Grafana Explore with a query for the namespace",
+	Long: `Opens Grafana Explore on the resolved environment with a PromQL or LogQL query prefilled.
+Use a named query from the config, an ad-hoc --query, or pick one interactively.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeQuery,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("❌ Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		target := resolveTarget(cfg)
+		if target == nil {
+			return
+		}
+		if err := applyTimeRange(target); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		var query config.Query
+		switch {
+		case flagQuery != "":
+			query = config.Query{Expr: flagQuery}
+		case len(args) == 1:
+			query, err = cfg.ResolveQuery(&target.Env, args[0])
+		default:
+			names := cfg.QueryNames(&target.Env)
+			if len(names) == 0 {
+				fmt.Println("⚠️  No queries configured. Pass one with --query or add 'queries:' to the config.")
+				os.Exit(1)
+			}
+			name, pickErr := ui.SelectString("Select Query", names)
+			if pickErr != nil {
+				return
+			}
+			query, err = cfg.ResolveQuery(&target.Env, name)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if flagDatasource != "" {
+			query.Datasource = flagDatasource
+		}
+
+		target.Explore = &query
+		launch(cfg, *target)
+	},
+}
+
+// completeQuery suggests named queries for the env from -e or the current context.
+func completeQuery(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var env *config.Environment
+	if envFlag, _ := cmd.Flags().GetString("env"); envFlag != "" {
+		env = cfg.FindByAlias(envFlag)
+	} else if state, err := kube.GetCurrentState(); err == nil {
+		env, _ = kube.FindMatchingEnv(state, cfg)
+	}
+	if env == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	queries := cfg.QueriesFor(env)
+	var suggestions []string
+	for _, name := range cfg.QueryNames(env) {
+		suggestions = append(suggestions, fmt.Sprintf("%s\t%s", name, queries[name].Expr))
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	addTargetFlags(exploreCmd)
+	addTimeFlags(exploreCmd)
+	addOutputFlags(exploreCmd)
+	exploreCmd.Flags().StringVarP(&flagQuery, "query", "q", "", "Ad-hoc PromQL/LogQL query (Go template, e.g. '{namespace=\"{{.Namespace}}\"}')")
+	exploreCmd.Flags().StringVar(&flagDatasource, "datasource", "", "Datasource for the query: prometheus or loki")
+
+	_ = exploreCmd.RegisterFlagCompletionFunc("datasource", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{config.QueryPrometheus, config.QueryLoki}, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(exploreCmd)
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/history"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/launcher"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagHistoryPick  bool // -i
+	flagHistoryLimit int  // --limit
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [n]",
+	Short: "List recently opened dashboards, or open one again",
+	Long: `Lists the environments, namespaces and dashboards you opened, most recent first.
+Pass an entry's number to open it again, or pick one with -i. "grafana-connect -" opens #2,
+the one before the last.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("❌ Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		entries, err := history.Load()
+		if err != nil {
+			fmt.Printf("❌ Could not read history: %v\n", err)
+			os.Exit(1)
+		}
+		recent := history.Distinct(entries)
+		if len(recent) == 0 {
+			fmt.Println("📭 Nothing opened yet.")
+			return
+		}
+
+		var picked history.Entry
+		switch {
+		case len(args) == 1:
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > len(recent) {
+				fmt.Printf("❌ No history entry %q (1-%d)\n", args[0], len(recent))
+				os.Exit(1)
+			}
+			picked = recent[n-1]
+
+		case flagHistoryPick:
+			ranked := append([]history.Entry(nil), recent...)
+			history.SortByFrecency(ranked, history.Frecency(entries, history.Entry.Key), history.Entry.Key)
+			labels := make([]string, len(ranked))
+			byLabel := map[string]history.Entry{}
+			for i, e := range ranked {
+				labels[i] = fmt.Sprintf("%s / %s / %s", e.Env, e.Namespace, describeOpened(cfg, e))
+				byLabel[labels[i]] = e
+			}
+			label, err := ui.SelectString("Open Again", labels)
+			if err != nil {
+				return
+			}
+			picked = byLabel[label]
+
+		default:
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for i, e := range recent {
+				if flagHistoryLimit > 0 && i == flagHistoryLimit {
+					break
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t×%d\n", i+1, ago(e.Time), e.Env, e.Namespace, describeOpened(cfg, e), e.Uses)
+			}
+			w.Flush()
+			return
+		}
+
+		target, err := historyTarget(cfg, picked)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if err := applyTimeRange(target); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		launch(cfg, *target)
+	},
+}
+
+// previousTarget is the target opened before the last one, for "grafana-connect -".
+func previousTarget(cfg *config.Config) (*launcher.Target, error) {
+	entries, err := history.Load()
+	if err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+	e, ok := history.Previous(entries)
+	if !ok {
+		return nil, fmt.Errorf("no previous target in the history yet")
+	}
+	return historyTarget(cfg, e)
+}
+
+// historyTarget rebuilds the target of a history entry from the current config, so a
+// named dashboard gets its current variables.
+func historyTarget(cfg *config.Config, e history.Entry) (*launcher.Target, error) {
+	env := cfg.FindByName(e.Env)
+	if env == nil {
+		return nil, fmt.Errorf("environment %q is no longer in the config", e.Env)
+	}
+	t := &launcher.Target{Env: cfg.Effective(env), Namespace: e.Namespace, Context: e.Context, Cluster: e.Cluster, Pod: e.Pod}
+	if e.Workload != "" {
+		kind, name, err := kube.ParseWorkloadRef(e.Workload)
+		if err != nil {
+			return nil, err
+		}
+		t.Deployment, t.WorkloadKind = name, kind
+	}
+
+	if e.Query != "" {
+		t.Explore = &config.Query{Expr: e.Query, Datasource: e.Datasource}
+		return t, nil
+	}
+	t.Dashboard = config.Dashboard{Path: e.Dashboard}
+	if name := dashboardName(cfg, &t.Env, e.Dashboard); name != "" {
+		t.Dashboard = cfg.DashboardsFor(&t.Env)[name]
+	}
+	return t, nil
+}
+
+// recordLaunch adds the target to the history. Failing to is only worth a warning.
+func recordLaunch(t launcher.Target) {
+	e := history.Entry{Env: t.Env.Name, Namespace: t.Namespace, Context: t.Context, Cluster: t.Cluster, Pod: t.Pod}
+	if t.Deployment != "" {
+		e.Workload = t.Deployment
+		if t.WorkloadKind != "" {
+			e.Workload = kube.Workload{Kind: t.WorkloadKind, Name: t.Deployment}.String()
+		}
+	}
+	if t.Explore != nil {
+		e.Query, e.Datasource = t.Explore.Expr, t.Explore.Datasource
+	} else {
+		e.Dashboard = t.Dashboard.Path
+	}
+	if err := history.Record(e); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not record history: %v\n", err)
+	}
+}
+
+// rankEnvironments returns envs with the most frecently opened first.
+func rankEnvironments(envs []config.Environment) []config.Environment {
+	entries, _ := history.Load()
+	scores := history.Frecency(entries, func(e history.Entry) string { return e.Env })
+	ranked := append([]config.Environment(nil), envs...)
+	history.SortByFrecency(ranked, scores, func(env config.Environment) string { return env.Name })
+	return ranked
+}
+
+// rankNamespaces returns namespaces with the most frecently opened first, counting the
+// launches of env, or of the state's context when env is nil.
+func rankNamespaces(state *kube.KubeState, env *config.Environment, namespaces []string) []string {
+	entries, _ := history.Load()
+	scores := history.Frecency(entries, func(e history.Entry) string {
+		if (env != nil && e.Env == env.Name) || (env == nil && state != nil && e.Context == state.Context) {
+			return e.Namespace
+		}
+		return ""
+	})
+	ranked := append([]string(nil), namespaces...)
+	history.SortByFrecency(ranked, scores, func(ns string) string { return ns })
+	return ranked
+}
+
+// dashboardName is the name env's dashboard with this path goes by, preferring the
+// default one, or "" if it has none.
+func dashboardName(cfg *config.Config, env *config.Environment, path string) string {
+	all := cfg.DashboardsFor(env)
+	if name := cfg.DefaultDashboardName(env); name != "" && all[name].Path == path {
+		return name
+	}
+	for _, name := range cfg.DashboardNames(env) {
+		if all[name].Path == path {
+			return name
+		}
+	}
+	return ""
+}
+
+// describeOpened says what an entry opened: the dashboard (by name when it has one) or
+// the Explore query, then the workload and pod.
+func describeOpened(cfg *config.Config, e history.Entry) string {
+	var s string
+	switch {
+	case e.Query != "":
+		s = "explore: " + e.Query
+	case e.Dashboard != "":
+		s = e.Dashboard
+		if env := cfg.FindByName(e.Env); env != nil {
+			eff := cfg.Effective(env)
+			if name := dashboardName(cfg, &eff, e.Dashboard); name != "" {
+				s = name
+			}
+		}
+	}
+	if e.Workload != "" {
+		s += " " + e.Workload
+	}
+	if e.Pod != "" {
+		s += " pod/" + e.Pod
+	}
+	return s
+}
+
+// ago formats how long ago t was, e.g. "5m ago".
+func ago(t time.Time) string {
+	switch d := time.Since(t); {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+func init() {
+	addTimeFlags(historyCmd)
+	addOutputFlags(historyCmd)
+	historyCmd.Flags().BoolVarP(&flagHistoryPick, "interactive", "i", false, "Pick the entry to open interactively")
+	historyCmd.Flags().IntVar(&flagHistoryLimit, "limit", 20, "How many entries to list (0 for all)")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -26,17 +26,18 @@ var listCmd = &cobra.Command{
 			return
 		}
 
-		// The Fuzzy Finder Logic
+		// The Fuzzy Finder Logic, most frecently opened first
+		envs := rankEnvironments(cfg.Environments)
 		idx, err := fuzzyfinder.Find(
-			cfg.Environments,
+			envs,
 			func(i int) string {
-				return cfg.Environments[i].Name
+				return envs[i].Name
 			},
 			fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
 				if i == -1 {
 					return ""
 				}
-				env := cfg.Environments[i]
+				env := envs[i]
 
 				// Build a nice preview string
 				return fmt.Sprintf(
@@ -45,12 +46,14 @@ var listCmd = &cobra.Command{
 						"🔗 URL:      %s\n"+
 						"🆔 PromUID:  %s\n"+
 						"👤 User:     %s\n"+
-						"🔍 Matcher:  %s\n",
+						"🔍 Matcher:  %s\n"+
+						"📊 Dashboards:\n%s",
 					strings.ToUpper(env.Name),
 					env.BaseURL,
 					env.PrometheusUID,
 					env.Username,
 					env.ContextMatch,
+					previewDashboards(cfg, &env),
 				)
 			}),
 		)
@@ -61,11 +64,35 @@ var listCmd = &cobra.Command{
 		}
 
 		// Launch with 'default' namespace since we are in manual mode
-		selectedEnv := cfg.Environments[idx]
-		launcher.Open(selectedEnv, "default")
+		target := launcher.Target{Env: cfg.Effective(&envs[idx]), Namespace: "default"}
+		if err := applyDashboard(cfg, &target, ""); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		launch(cfg, target)
 	},
 }
 
+// previewDashboards lists the env's dashboards, marking the default one.
+func previewDashboards(cfg *config.Config, env *config.Environment) string {
+	all := cfg.DashboardsFor(env)
+	if len(all) == 0 {
+		return "   (built-in)\n"
+	}
+	def := cfg.DefaultDashboardName(env)
+
+	var b strings.Builder
+	for _, name := range cfg.DashboardNames(env) {
+		marker := " "
+		if name == def {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, " %s %-10s %s\n", marker, name, all[name].Path)
+	}
+	return b.String()
+}
+
 func init() {
+	addOutputFlags(listCmd)
 	rootCmd.AddCommand(listCmd)
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/cache"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/grafana"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/ui"
+)
+
+var flagRefresh bool // --refresh
+
+// defaultNamespaceTTL applies when namespace_cache_ttl isn't set.
+const defaultNamespaceTTL = 5 * time.Minute
+
+// listNamespaces returns the namespaces of the state's context and where they came from
+// (a kube.Source* constant). What the cluster returns is cached: entries older than the
+// TTL are still served, and refreshed by a background process for next time; --refresh
+// always asks the cluster. When the cluster can't be asked, the cache, the kubeconfig
+// contexts and envNamespaces are tried in turn. state is nil when no context maps to
+// env, the effective environment (see effectiveEnv); env may be nil. ttl comes from
+// namespaceCacheTTL.
+func listNamespaces(state *kube.KubeState, env *config.Environment, ttl time.Duration) ([]string, string, error) {
+	if state == nil {
+		if env == nil {
+			return nil, "", fmt.Errorf("no kubeconfig context to list namespaces from")
+		}
+		return envNamespaces(env, fmt.Errorf("no kubeconfig context found for %s", env.Name))
+	}
+
+	key := cache.Key(state.Context, state.Server)
+
+	if ttl > 0 && !flagRefresh {
+		if e, err := cache.Load(cache.Namespaces, key); err == nil {
+			if e.Age() > ttl {
+				_ = cache.SpawnRefresh(cache.Namespaces, key, refreshArgs(state)...)
+			}
+			return e.Values, e.Source, nil
+		}
+	}
+
+	namespaces, source, err := kube.ClusterNamespaces(state)
+	if err == nil {
+		if ttl > 0 {
+			_ = cache.Store(cache.Namespaces, key, source, namespaces)
+		}
+		return namespaces, source, nil
+	}
+
+	// Cluster unreachable (VPN down?): an old list beats none
+	if e, cerr := cache.Load(cache.Namespaces, key); cerr == nil {
+		return e.Values, e.Source, nil
+	}
+	if namespaces := kube.KubeconfigNamespaces(state); len(namespaces) > 0 {
+		return namespaces, kube.SourceKubeconfig, nil
+	}
+	return envNamespaces(env, err)
+}
+
+// envNamespaces are the namespaces known without kube access: the namespace label
+// values of env's Prometheus, asked through Grafana, else env's namespaces list.
+// err is returned when there are none.
+func envNamespaces(env *config.Environment, err error) ([]string, string, error) {
+	if env == nil {
+		return nil, "", err
+	}
+	if env.PrometheusUID != "" {
+		if namespaces, gerr := grafanaNamespaces(env); gerr == nil && len(namespaces) > 0 {
+			return namespaces, kube.SourceGrafana, nil
+		}
+	}
+	if len(env.Namespaces) > 0 {
+		return env.Namespaces, kube.SourceConfig, nil
+	}
+	return nil, "", err
+}
+
+// grafanaNamespaces asks Grafana for the values of the namespace label in env's Prometheus.
+func grafanaNamespaces(env *config.Environment) ([]string, error) {
+	client, err := grafana.ForEnv(*env)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return client.LabelValues(ctx, env.PrometheusUID, "namespace")
+}
+
+// effectiveEnv is cfg.Effective(env) (e.g. with the default username for Grafana),
+// or nil when env is.
+func effectiveEnv(cfg *config.Config, env *config.Environment) *config.Environment {
+	if env == nil {
+		return nil
+	}
+	e := cfg.Effective(env)
+	return &e
+}
+
+// pickNamespace lets the user pick one of the namespaces of state's context (or of env
+// when state is nil), or type one in when they can't be listed.
+func pickNamespace(cfg *config.Config, state *kube.KubeState, env *config.Environment) (string, error) {
+	def := "default"
+	if state != nil {
+		def = state.Namespace
+		fmt.Fprintf(os.Stderr, "📡 Fetching namespaces from [%s]...\n", state.Context)
+	} else {
+		fmt.Fprintf(os.Stderr, "📡 No kubeconfig context for %s, asking Grafana for namespaces...\n", env.Name)
+	}
+	namespaces, source, err := listNamespaces(state, effectiveEnv(cfg, env), namespaceCacheTTL(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not list namespaces: %v\n", err)
+		return ui.PromptNamespace(def)
+	}
+	return ui.SelectNamespace(rankNamespaces(state, env, namespaces), source)
+}
+
+// refreshArgs are the kubeconfig flags the background refresh needs to reach the same cluster.
+func refreshArgs(state *kube.KubeState) []string {
+	args := []string{"--context", state.Context}
+	if kubeFlags.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeFlags.Kubeconfig)
+	}
+	if kubeFlags.Cluster != "" {
+		args = append(args, "--cluster", kubeFlags.Cluster)
+	}
+	return args
+}
+
+// namespaceCacheTTL is cfg's namespace_cache_ttl. cfg may be nil.
+func namespaceCacheTTL(cfg *config.Config) time.Duration {
+	if cfg == nil || cfg.NamespaceCacheTTL == "" {
+		return defaultNamespaceTTL
+	}
+	ttl, err := time.ParseDuration(cfg.NamespaceCacheTTL)
+	if err != nil {
+		return defaultNamespaceTTL
+	}
+	return ttl
+}
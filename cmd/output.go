@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/clipboard"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/grafana"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/launcher"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagPrint   bool   // --print
+	flagCopyURL bool   // --copy-url
+	flagOutput  string // -o
+	flagShare   bool   // --share
+)
+
+// addOutputFlags registers the flags that replace opening the browser.
+func addOutputFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&flagPrint, "print", false, "Print the URL to stdout instead of opening it")
+	c.Flags().BoolVar(&flagCopyURL, "copy-url", false, "Copy the URL (not the password) to the clipboard instead of opening it")
+	c.Flags().StringVarP(&flagOutput, "output", "o", "", "Output format instead of opening the browser: json")
+	c.Flags().BoolVar(&flagShare, "share", false, "Create a Grafana short link and print it (combine with --copy-url to copy it)")
+	c.MarkFlagsMutuallyExclusive("print", "output")
+
+	_ = c.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// launch builds the target's URL and then opens, prints, copies or describes it
+// depending on the output flags. Only targets that made it that far go into the history.
+func launch(cfg *config.Config, t launcher.Target) {
+	if flagOutput != "" && flagOutput != "json" {
+		fmt.Fprintf(os.Stderr, "❌ Unknown output format: %s (supported: json)\n", flagOutput)
+		os.Exit(1)
+	}
+	defaults := cfg.EffectiveDefaults()
+	if err := clipboard.Use(defaults.Clipboard); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+	if defaults.ClipboardClearAfter != "" {
+		d, err := time.ParseDuration(defaults.ClipboardClearAfter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Invalid clipboard_clear_after: %v\n", err)
+		}
+		clipboard.SetClearAfter(d)
+	}
+
+	// Plain launch: password on the clipboard, browser opens
+	if !flagPrint && !flagCopyURL && !flagShare && flagOutput == "" {
+		if err := launcher.Open(t); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		recordLaunch(t)
+		return
+	}
+
+	finalURL, err := launcher.BuildURL(t)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	// With --share, the short link is what gets printed or copied
+	summary := launcher.Summarize(t, finalURL)
+	outURL := finalURL
+	if flagShare {
+		summary.ShortURL = shorten(t.Env, finalURL)
+		outURL = summary.ShortURL
+	}
+
+	if flagCopyURL {
+		if err := launcher.CopyURL(outURL); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Clipboard error: %v\n", err)
+		} else if !flagPrint && flagOutput == "" {
+			fmt.Fprintf(os.Stderr, "📋 URL for %s [%s] copied to clipboard!\n", t.Env.Name, t.Namespace)
+		}
+	}
+
+	switch {
+	case flagOutput == "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(summary); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	case flagPrint || flagShare:
+		fmt.Println(outURL)
+	}
+	recordLaunch(t)
+}
+
+// shorten asks the env's Grafana for a short link, falling back to the long URL.
+func shorten(env config.Environment, longURL string) string {
+	client, err := grafana.ForEnv(env)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var short string
+		if short, err = client.ShortURL(ctx, longURL); err == nil {
+			return short
+		}
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  Could not create short link (%v), using the full URL\n", err)
+	return longURL
+}
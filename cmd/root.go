@@ -1,14 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 
+	"github.com/ktr0731/go-fuzzyfinder"
 	"github.com/spf13/cobra"
 
 	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
 	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
 	"github.com/PraveenPrabhuT/grafana-connect/internal/launcher"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/timerange"
 	"github.com/PraveenPrabhuT/grafana-connect/internal/ui"
 )
 
@@ -17,12 +21,29 @@ var (
 	flagInteractiveCtx bool   // -I
 	flagAlias          string // -e
 	flagNamespace      string // -n
+	flagDashboard      string // -d
+	flagPickDashboard  bool   // --pick-dashboard
+
+	flagFrom   string // --from
+	flagTo     string // --to
+	flagLast   string // --last
+	flagAt     string // --at
+	flagWindow string // --window
+
+	kubeFlags kube.Overrides // --kubeconfig, --context, --cluster
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "grafana-connect",
+	Use:   "grafana-connect [-]",
 	Short: "Context-aware Grafana launcher",
-	Long:  `Automatically detects your K8s context and opens the relevant Grafana dashboard with filters applied.`,
+	Long: `Automatically detects your K8s context and opens the relevant Grafana dashboard with filters applied.
+"grafana-connect -" goes back to the previously opened target, like "cd -".`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 && args[0] == "-" {
+			return nil
+		}
+		return cobra.NoArgs(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// 1. Load Configuration
 		cfg, err := config.LoadConfig()
@@ -31,167 +52,398 @@ var rootCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		var targetEnv *config.Environment
-		var targetNamespace string
+		var target *launcher.Target
+		if len(args) == 1 {
+			// "-": back to the previous target
+			target, err = previousTarget(cfg)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			target = resolveTarget(cfg)
+			if target == nil {
+				return
+			}
+			if err := applyDashboard(cfg, target, flagDashboard); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		// --- LOGIC FLOW ---
+		if err := applyTimeRange(target); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
 
-		// 1. Check for Alias Flag (-e)
-		if flagAlias != "" {
-			targetEnv = cfg.FindByAlias(flagAlias)
-			if targetEnv == nil {
-				fmt.Printf("❌ No environment found with alias: '%s'\n", flagAlias)
+		if flagPickDashboard {
+			dash, err := pickDashboard(target.Env)
+			if errors.Is(err, fuzzyfinder.ErrAbort) {
+				return
+			}
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
 				os.Exit(1)
 			}
-			// Default NS for alias mode is "default" unless overridden later
-			targetNamespace = "default"
+			target.Dashboard = config.Dashboard{Path: dash}
 		}
 
-		// 2. Check for Interactive Flags (-I / -i) ONLY if alias wasn't provided
+		// Final Launch
+		launch(cfg, *target)
+	},
+}
+
+// resolveTarget works out the environment, namespace and kube context from -e, -I, -i, -n
+// or the current kube context. It exits on hard errors and returns nil if the user aborted.
+func resolveTarget(cfg *config.Config) *launcher.Target {
+	var targetEnv *config.Environment
+	var targetState *kube.KubeState // best effort in -e / -I modes
+	var targetNamespace string
+
+	// --- LOGIC FLOW ---
+
+	// 1. Check for Alias Flag (-e)
+	if flagAlias != "" {
+		targetEnv = cfg.FindByAlias(flagAlias)
 		if targetEnv == nil {
-			if flagInteractiveCtx {
-				// -I: Full Selection
-				env, err := ui.SelectEnvironment(cfg.Environments)
-				if err != nil {
-					return
-				}
-				targetEnv = env
-
-				// Resolve context for NS fetching
-				ctxName, err := kube.FindContextByRegex(env.ContextMatch)
-				if err == nil {
-					// Only try to fetch namespaces if we found a matching local context
-					fmt.Printf("📡 Fetching namespaces from [%s]...\n", ctxName)
-					nss, err := kube.GetNamespaces(ctxName)
-					if err == nil {
-						targetNamespace, _ = ui.SelectString("Select Namespace", nss)
-					}
-				}
-				if targetNamespace == "" {
-					targetNamespace = "default"
-				}
-
-			} else if flagInteractiveNs {
-				// === MODE: -i (Current Context -> Choose NS) ===
-
-				// A. Get Current State
-				state, err := kube.GetCurrentState()
-				if err != nil {
-					fmt.Printf("❌ Could not detect K8s state: %v\n", err)
-					os.Exit(1)
-				}
-				targetEnv, _ = kube.FindMatchingEnv(state.Context, cfg)
-
-				fmt.Printf("📡 Fetching namespaces from [%s]...\n", state.Context)
-				nss, err := kube.GetNamespaces(state.Context)
-				if err == nil {
-					targetNamespace, _ = ui.SelectString("Select Namespace", nss)
-				}
-			}
+			fmt.Printf("❌ No environment found with alias: '%s'\n", flagAlias)
+			os.Exit(1)
 		}
+		// Default NS for alias mode is "default" unless overridden later
+		targetNamespace = "default"
+		targetState, _ = kube.FindStateForEnv(targetEnv)
+	}
 
-		// 3. Fallback to Auto-Detect
-		if targetEnv == nil && !flagInteractiveNs {
+	// 2. Check for Interactive Flags (-I / -i) ONLY if alias wasn't provided
+	if targetEnv == nil {
+		if flagInteractiveCtx {
+			// -I: Full Selection
+			env, err := ui.SelectEnvironment(rankEnvironments(cfg.Environments))
+			if err != nil {
+				return nil
+			}
+			targetEnv = env
+
+			// Resolve context for NS fetching. Without a matching local context the
+			// namespaces come from Grafana instead.
+			state, err := kube.FindStateForEnv(env)
+			if err == nil {
+				targetState = state
+			}
+			targetNamespace, _ = pickNamespace(cfg, targetState, env)
+			if targetNamespace == "" {
+				targetNamespace = "default"
+			}
+
+		} else if flagInteractiveNs {
+			// === MODE: -i (Current Context -> Choose NS) ===
+
+			// A. Get Current State
 			state, err := kube.GetCurrentState()
 			if err != nil {
 				fmt.Printf("❌ Could not detect K8s state: %v\n", err)
 				os.Exit(1)
 			}
-			targetEnv, err = kube.FindMatchingEnv(state.Context, cfg)
-			if err != nil {
-				fmt.Printf("⚠️  No mapping found for context: %s\n", state.Context)
+			targetEnv, err = kube.FindMatchingEnv(state, cfg)
+			var ambiguous *kube.AmbiguousMatchError
+			if errors.As(err, &ambiguous) {
+				fmt.Printf("❌ %v\n", err)
 				os.Exit(1)
 			}
-			targetNamespace = state.Namespace
+			targetState = state
+			targetNamespace, _ = pickNamespace(cfg, state, targetEnv)
 		}
+	}
 
-		// 4. Apply Namespace Override Flag (-n)
-		// This applies to ANY mode above (Alias, Interactive, or Auto)
-		if flagNamespace != "" {
-			targetNamespace = flagNamespace
+	// 3. Fallback to Auto-Detect
+	if targetEnv == nil && !flagInteractiveNs {
+		state, err := kube.GetCurrentState()
+		if err != nil {
+			fmt.Printf("❌ Could not detect K8s state: %v\n", err)
+			os.Exit(1)
 		}
-
-		// Final Launch
-		if targetEnv != nil {
-			launcher.Open(*targetEnv, targetNamespace)
+		targetEnv, err = kube.FindMatchingEnv(state, cfg)
+		if err != nil {
+			var ambiguous *kube.AmbiguousMatchError
+			if errors.As(err, &ambiguous) {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("⚠️  No mapping found for context: %s\n", state.Context)
+			}
+			os.Exit(1)
 		}
-	},
+		targetState = state
+		targetNamespace = state.Namespace
+	}
+
+	if targetEnv == nil {
+		return nil
+	}
+
+	// 4. Apply Namespace Override Flag (-n)
+	// This applies to ANY mode above (Alias, Interactive, or Auto)
+	if flagNamespace != "" {
+		targetNamespace = flagNamespace
+	}
+
+	target := &launcher.Target{Env: cfg.Effective(targetEnv), Namespace: targetNamespace}
+	if targetState != nil {
+		target.Context = targetState.Context
+		target.Cluster = targetState.Cluster
+	}
+
+	// 5. Workload / Pod (--workload / --pod, or picked in interactive modes)
+	if err := applyWorkload(target, flagInteractiveNs || flagInteractiveCtx); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return target
 }
 
 func init() {
 	// 1. Define Flags
-	rootCmd.Flags().BoolVarP(&flagInteractiveNs, "interactive-ns", "i", false, "Pick namespace interactively")
-	rootCmd.Flags().BoolVarP(&flagInteractiveCtx, "interactive-full", "I", false, "Pick environment and namespace interactively")
-	rootCmd.Flags().StringVarP(&flagAlias, "env", "e", "", "Select environment by alias (e.g. 'prod')")
-	rootCmd.Flags().StringVarP(&flagNamespace, "namespace", "n", "", "Override namespace")
-
-	// 2. Register Autocomplete for --env / -e
-	// FIX: Clean list. Only show Alias if it exists. Show Name only if no Alias exists.
-	_ = rootCmd.RegisterFlagCompletionFunc("env", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveError
-		}
+	addTargetFlags(rootCmd)
+	addTimeFlags(rootCmd)
+	addOutputFlags(rootCmd)
+	rootCmd.Flags().StringVarP(&flagDashboard, "dashboard", "d", "", "Open a named dashboard (e.g. 'jvm')")
+	rootCmd.Flags().BoolVar(&flagPickDashboard, "pick-dashboard", false, "Pick any dashboard from Grafana instead of the configured one")
 
-		var suggestions []string
-		for _, env := range cfg.Environments {
-			if env.Alias != "" {
-				// Format: "alias\tDescription"
-				suggestions = append(suggestions, fmt.Sprintf("%s\t%s", env.Alias, env.Name))
-			} else {
-				suggestions = append(suggestions, fmt.Sprintf("%s\tEnvironment", env.Name))
-			}
-		}
-		return suggestions, cobra.ShellCompDirectiveNoFileComp
-	})
+	_ = rootCmd.RegisterFlagCompletionFunc("dashboard", completeDashboard)
 
-	// 3. Register Autocomplete for --namespace / -n
-	// FIX: Strict Logic. Only fallback to current context if -e is NOT present.
-	_ = rootCmd.RegisterFlagCompletionFunc("namespace", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		// A. Check for --env flag
-		envFlag, _ := cmd.Flags().GetString("env")
+	// kubectl's global flags, for every command (and completions)
+	rootCmd.PersistentFlags().StringVar(&kubeFlags.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use (default $KUBECONFIG or ~/.kube/config)")
+	rootCmd.PersistentFlags().StringVar(&kubeFlags.Context, "context", "", "The kubeconfig context to use")
+	rootCmd.PersistentFlags().StringVar(&kubeFlags.Cluster, "cluster", "", "The kubeconfig cluster to use")
+	_ = rootCmd.RegisterFlagCompletionFunc("context", completeContext)
+	_ = rootCmd.RegisterFlagCompletionFunc("cluster", completeCluster)
+	kube.UseOverrides(&kubeFlags)
 
-		var contextToQuery string
+	// Unknown keys don't stop a launch; `config validate` is where they fail
+	config.OnWarning(warnConfig)
+}
 
-		if envFlag != "" {
-			// === PATH 1: User specified an Alias ===
-			cfg, err := config.LoadConfig()
-			if err != nil {
-				return nil, cobra.ShellCompDirectiveError
-			}
+// warnConfig prints a config problem LoadConfig could ignore. Completions stay quiet.
+func warnConfig(p config.Problem) {
+	if len(os.Args) > 1 && (os.Args[1] == cobra.ShellCompRequestCmd || os.Args[1] == cobra.ShellCompNoDescRequestCmd) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  %s (ignored, see 'grafana-connect config validate')\n", p)
+}
 
-			targetEnv := cfg.FindByAlias(envFlag)
-			if targetEnv == nil {
-				// User typed an alias that doesn't exist. We can't autocomplete.
-				return nil, cobra.ShellCompDirectiveNoFileComp
-			}
+// applyDashboard sets the target's dashboard to the named one (or the env's default).
+func applyDashboard(cfg *config.Config, t *launcher.Target, name string) error {
+	d, err := cfg.ResolveDashboard(&t.Env, name)
+	if err != nil {
+		return err
+	}
+	t.Dashboard = d
+	return nil
+}
 
-			// Find the actual Kube Context name from the regex
-			ctx, err := kube.FindContextByRegex(targetEnv.ContextMatch)
-			if err != nil {
-				// Valid Alias, but regex didn't match any local kubeconfig context.
-				// We cannot autocomplete namespaces if we can't find the cluster.
-				return nil, cobra.ShellCompDirectiveNoFileComp
+// addTargetFlags registers the env/namespace selection flags (and their completions)
+// shared by every command that resolves a target through resolveTarget.
+func addTargetFlags(c *cobra.Command) {
+	c.Flags().BoolVarP(&flagInteractiveNs, "interactive-ns", "i", false, "Pick namespace interactively")
+	c.Flags().BoolVarP(&flagInteractiveCtx, "interactive-full", "I", false, "Pick environment and namespace interactively")
+	c.Flags().StringVarP(&flagAlias, "env", "e", "", "Select environment by alias (e.g. 'prod')")
+	c.Flags().StringVarP(&flagNamespace, "namespace", "n", "", "Override namespace")
+	c.Flags().BoolVar(&flagRefresh, "refresh", false, "Fetch namespaces from the cluster instead of the cache")
+
+	_ = c.RegisterFlagCompletionFunc("env", completeEnv)
+	_ = c.RegisterFlagCompletionFunc("namespace", completeNamespace)
+
+	addWorkloadFlags(c)
+}
+
+// addTimeFlags registers the time range flags applied by applyTimeRange.
+func addTimeFlags(c *cobra.Command) {
+	c.Flags().StringVar(&flagFrom, "from", "", "Start of the time range (now-6h, RFC3339 or unix ms)")
+	c.Flags().StringVar(&flagTo, "to", "", "End of the time range (now, RFC3339 or unix ms)")
+	c.Flags().StringVar(&flagLast, "last", "", "Shorthand for --from now-<duration> --to now (e.g. 2h, 7d)")
+	c.Flags().StringVar(&flagAt, "at", "", "Center the time range on this timestamp (RFC3339 or unix ms)")
+	c.Flags().StringVar(&flagWindow, "window", "30m", "Width of the time range used with --at")
+
+	c.MarkFlagsMutuallyExclusive("last", "from")
+	c.MarkFlagsMutuallyExclusive("last", "to")
+	c.MarkFlagsMutuallyExclusive("at", "from")
+	c.MarkFlagsMutuallyExclusive("at", "to")
+	c.MarkFlagsMutuallyExclusive("at", "last")
+}
+
+// applyTimeRange validates the time range flags and stores the result on the target.
+// Without flags the dashboard/env defaults apply.
+func applyTimeRange(t *launcher.Target) error {
+	var r timerange.Range
+	var err error
+
+	switch {
+	case flagLast != "":
+		r, err = timerange.Last(flagLast)
+	case flagAt != "":
+		r, err = timerange.Around(flagAt, flagWindow)
+	default:
+		if flagFrom != "" {
+			if r.From, err = timerange.ParseTime(flagFrom); err != nil {
+				return fmt.Errorf("--from: %w", err)
+			}
+		}
+		if flagTo != "" {
+			if r.To, err = timerange.ParseTime(flagTo); err != nil {
+				return fmt.Errorf("--to: %w", err)
 			}
-			contextToQuery = ctx
+		}
+	}
+	if err != nil {
+		return err
+	}
 
+	if r.From != "" {
+		t.From = r.From
+	}
+	if r.To != "" {
+		t.To = r.To
+	}
+	return nil
+}
+
+// completeEnv suggests aliases for --env / -e.
+// FIX: Clean list. Only show Alias if it exists. Show Name only if no Alias exists.
+func completeEnv(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var suggestions []string
+	for _, env := range cfg.Environments {
+		if env.Alias != "" {
+			// Format: "alias\tDescription"
+			suggestions = append(suggestions, fmt.Sprintf("%s\t%s", env.Alias, env.Name))
 		} else {
-			// === PATH 2: No Alias, use Current Context ===
-			state, err := kube.GetCurrentState()
-			if err != nil {
-				return nil, cobra.ShellCompDirectiveError
-			}
-			contextToQuery = state.Context
+			suggestions = append(suggestions, fmt.Sprintf("%s\tEnvironment", env.Name))
 		}
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeContext(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	states, err := kube.ListContexts()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(states))
+	for _, s := range states {
+		names = append(names, fmt.Sprintf("%s\t%s", s.Context, s.Cluster))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
 
-		// B. Fetch Namespaces from the determined context
-		namespaces, err := kube.GetNamespaces(contextToQuery)
+func completeCluster(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := kube.ListClusters()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionState finds the kube context completions should query: the one matching -e,
+// or the current one. On failure it returns the directive to hand back to cobra.
+// FIX: Strict Logic. Only fallback to current context if -e is NOT present.
+func completionState(cmd *cobra.Command) (*kube.KubeState, cobra.ShellCompDirective, bool) {
+	// A. Check for --env flag
+	envFlag, _ := cmd.Flags().GetString("env")
+
+	if envFlag != "" {
+		// === PATH 1: User specified an Alias ===
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError, false
+		}
+
+		targetEnv := cfg.FindByAlias(envFlag)
+		if targetEnv == nil {
+			// User typed an alias that doesn't exist. We can't autocomplete.
+			return nil, cobra.ShellCompDirectiveNoFileComp, false
+		}
+
+		// Find the actual Kube Context from the env matchers
+		state, err := kube.FindStateForEnv(targetEnv)
 		if err != nil {
-			return nil, cobra.ShellCompDirectiveError
+			// Valid Alias, but no local kubeconfig context matched the env.
+			// We cannot autocomplete if we can't find the cluster.
+			return nil, cobra.ShellCompDirectiveNoFileComp, false
+		}
+		return state, cobra.ShellCompDirectiveNoFileComp, true
+	}
+
+	// === PATH 2: No Alias, use Current Context ===
+	state, err := kube.GetCurrentState()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError, false
+	}
+	return state, cobra.ShellCompDirectiveNoFileComp, true
+}
+
+// completeNamespace suggests namespaces for --namespace / -n.
+func completeNamespace(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	state, directive, ok := completionState(cmd)
+
+	// B. Fetch Namespaces from the determined context (the env's own sources as a last resort)
+	var env *config.Environment
+	cfg, err := config.LoadConfig()
+	if err == nil {
+		if envFlag, _ := cmd.Flags().GetString("env"); envFlag != "" {
+			env = cfg.FindByAlias(envFlag)
+		} else if ok {
+			env, _ = kube.FindMatchingEnv(state, cfg)
+		}
+	}
+	if !ok {
+		if env == nil {
+			return nil, directive
 		}
+		state = nil // -e without a local context: ask Grafana
+	}
+	namespaces, _, err := listNamespaces(state, effectiveEnv(cfg, env), namespaceCacheTTL(cfg))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return namespaces, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDashboard suggests dashboard names for --dashboard / -d.
+// Uses the env from -e, else the one matching the current context, else every known name.
+func completeDashboard(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var env *config.Environment
+	if envFlag, _ := cmd.Flags().GetString("env"); envFlag != "" {
+		env = cfg.FindByAlias(envFlag)
+	} else if state, err := kube.GetCurrentState(); err == nil {
+		env, _ = kube.FindMatchingEnv(state, cfg)
+	}
+
+	envs := cfg.Environments
+	if env != nil {
+		envs = []config.Environment{*env}
+	}
 
-		return namespaces, cobra.ShellCompDirectiveNoFileComp
-	})
+	seen := map[string]bool{}
+	var suggestions []string
+	for i := range envs {
+		for name, d := range cfg.DashboardsFor(&envs[i]) {
+			if !seen[name] {
+				seen[name] = true
+				suggestions = append(suggestions, fmt.Sprintf("%s\t%s", name, d.Path))
+			}
+		}
+	}
+	sort.Strings(suggestions)
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
 }
 
 func Execute() {
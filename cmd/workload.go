@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/launcher"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagWorkload string // --workload
+	flagPod      string // --pod
+)
+
+const (
+	allWorkloads = "(all workloads)"
+	allPods      = "(all pods)"
+)
+
+func addWorkloadFlags(c *cobra.Command) {
+	c.Flags().StringVar(&flagWorkload, "workload", "", "Filter on a workload (e.g. 'api' or 'statefulset/db')")
+	c.Flags().StringVar(&flagPod, "pod", "", "Filter on a pod")
+
+	_ = c.RegisterFlagCompletionFunc("workload", completeWorkload)
+	_ = c.RegisterFlagCompletionFunc("pod", completePod)
+}
+
+// applyWorkload fills the target's workload and pod from the flags, or lets the user
+// pick them when interactive and the cluster is reachable. Both stay empty ("All") otherwise.
+func applyWorkload(t *launcher.Target, interactive bool) error {
+	var workload *kube.Workload
+
+	switch {
+	case flagWorkload != "":
+		kind, name, err := kube.ParseWorkloadRef(flagWorkload)
+		if err != nil {
+			return err
+		}
+		t.Deployment, t.WorkloadKind = name, kind
+		// Resolve the real kind and selector when we can, for the pod picker
+		if t.Context != "" {
+			if w, err := kube.FindWorkload(t.Context, t.Namespace, flagWorkload); err == nil {
+				workload = w
+				t.WorkloadKind = w.Kind
+			}
+		}
+
+	case interactive && t.Context != "":
+		workloads, err := kube.ListWorkloads(t.Context, t.Namespace)
+		if err != nil || len(workloads) == 0 {
+			break
+		}
+		items := []string{allWorkloads}
+		for _, w := range workloads {
+			items = append(items, w.String())
+		}
+		// Esc means "all", not abort
+		picked, err := ui.SelectString("Select Workload", items)
+		if err != nil || picked == allWorkloads {
+			break
+		}
+		for i := range workloads {
+			if workloads[i].String() == picked {
+				workload = &workloads[i]
+			}
+		}
+		t.Deployment, t.WorkloadKind = workload.Name, workload.Kind
+	}
+
+	if flagPod != "" {
+		t.Pod = flagPod
+		return nil
+	}
+	if !interactive || workload == nil {
+		return nil
+	}
+
+	pods, err := kube.ListPods(t.Context, t.Namespace, workload)
+	if err != nil || len(pods) == 0 {
+		return nil
+	}
+	picked, err := ui.SelectString("Select Pod", append([]string{allPods}, pods...))
+	if err == nil && picked != allPods {
+		t.Pod = picked
+	}
+	return nil
+}
+
+// completionNamespace is the namespace workload/pod completions look in: -n, else the context's.
+func completionNamespace(cmd *cobra.Command, state *kube.KubeState) string {
+	if ns, _ := cmd.Flags().GetString("namespace"); ns != "" {
+		return ns
+	}
+	return state.Namespace
+}
+
+// completeWorkload suggests "kind/name" for --workload.
+func completeWorkload(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	state, directive, ok := completionState(cmd)
+	if !ok {
+		return nil, directive
+	}
+
+	workloads, err := kube.ListWorkloads(state.Context, completionNamespace(cmd, state))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var suggestions []string
+	for _, w := range workloads {
+		suggestions = append(suggestions, fmt.Sprintf("%s\t%s", w, w.Kind))
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePod suggests pods for --pod, limited to --workload when given.
+func completePod(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	state, directive, ok := completionState(cmd)
+	if !ok {
+		return nil, directive
+	}
+	namespace := completionNamespace(cmd, state)
+
+	var workload *kube.Workload
+	if ref, _ := cmd.Flags().GetString("workload"); ref != "" {
+		w, err := kube.FindWorkload(state.Context, namespace, ref)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		workload = w
+	}
+
+	pods, err := kube.ListPods(state.Context, namespace, workload)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return pods, cobra.ShellCompDirectiveNoFileComp
+}
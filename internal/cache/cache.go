@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/atomicfile"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/proc"
+)
+
+// Kinds of cached lists, each in its own directory.
+const (
+	Namespaces = "namespaces"
+)
+
+// lockTimeout is how long a refresh lock holds before it is considered abandoned.
+const lockTimeout = time.Minute
+
+// Entry is a cached list.
+type Entry struct {
+	Key       string    `json:"key"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Values    []string  `json:"values"`
+	Source    string    `json:"source,omitempty"` // where the values came from, e.g. "rbac"
+}
+
+// Age is how long ago the list was fetched.
+func (e *Entry) Age() time.Duration {
+	return time.Since(e.FetchedAt)
+}
+
+// Dir is $XDG_CACHE_HOME/grafana-connect, or ~/.cache/grafana-connect.
+func Dir() string {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(base, "grafana-connect")
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Key identifies a kube context. The API server is part of it, so two kubeconfigs
+// using the same context name for different clusters don't share entries.
+func Key(context, server string) string {
+	return context + "@" + server
+}
+
+// path is <dir>/<kind>/<readable name>-<hash>.json.
+func path(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := unsafeChars.ReplaceAllString(key, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return filepath.Join(Dir(), kind, name+"-"+hex.EncodeToString(sum[:4])+".json")
+}
+
+// Load returns the cached entry, whatever its age.
+func Load(kind, key string) (*Entry, error) {
+	data, err := os.ReadFile(path(kind, key))
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Key != key {
+		return nil, os.ErrNotExist // hash collision
+	}
+	return &e, nil
+}
+
+// Store saves values for key, replacing the previous entry atomically.
+func Store(kind, key, source string, values []string) error {
+	p := path(kind, key)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(Entry{Key: key, FetchedAt: time.Now(), Values: values, Source: source})
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(p, data, 0600)
+}
+
+// Lock marks a refresh of key as running. It returns false if another one already
+// is (and started less than a minute ago), so repeated completions don't pile up.
+func Lock(kind, key string) bool {
+	p := path(kind, key) + ".lock"
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return false
+	}
+	if info, err := os.Stat(p); err == nil && time.Since(info.ModTime()) > lockTimeout {
+		_ = os.Remove(p)
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// Unlock releases the lock taken by Lock.
+func Unlock(kind, key string) {
+	_ = os.Remove(path(kind, key) + ".lock")
+}
+
+// Clear removes every cached entry and returns how many there were.
+func Clear() (int, error) {
+	n := 0
+	err := filepath.WalkDir(Dir(), func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(p) == ".json" {
+			n++
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, os.RemoveAll(Dir())
+}
+
+// RefreshCommand is the hidden subcommand that refreshes an entry in the background.
+const RefreshCommand = "__cache-refresh"
+
+// SpawnRefresh starts a detached `grafana-connect __cache-refresh` for key unless one
+// is already running. extra is passed along (e.g. the kubeconfig flags).
+func SpawnRefresh(kind, key string, extra ...string) error {
+	if !Lock(kind, key) {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		Unlock(kind, key)
+		return err
+	}
+
+	args := append([]string{RefreshCommand, "--kind", kind, "--key", key}, extra...)
+	cmd := exec.Command(exe, args...)
+	proc.Detach(cmd)
+	if err := cmd.Start(); err != nil {
+		Unlock(kind, key)
+		return err
+	}
+	// Don't wait: the helper outlives us and releases the lock itself
+	return cmd.Process.Release()
+}
@@ -0,0 +1,119 @@
+package clipboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/proc"
+)
+
+// RestoreCommand is the hidden subcommand that runs the restore helper.
+const RestoreCommand = "__clipboard-restore"
+
+var clearAfter time.Duration
+
+// ErrNotCleared means the text was written but the auto-clear could not be scheduled.
+var ErrNotCleared = errors.New("clipboard will not be auto-cleared")
+
+// SetClearAfter makes WriteSecret schedule a restore of the previous clipboard
+// contents after d. Zero disables it.
+func SetClearAfter(d time.Duration) {
+	clearAfter = d
+}
+
+// restoreJob is handed to the helper on stdin so nothing sensitive shows up in ps.
+type restoreJob struct {
+	Secret      string `json:"secret"`
+	Previous    string `json:"previous"`
+	HadPrevious bool   `json:"had_previous"`
+}
+
+// WriteSecret writes text like Write and, if SetClearAfter was used, starts a detached
+// helper that puts the previous contents back once the timeout expires.
+func WriteSecret(text string) error {
+	b := Current()
+	if clearAfter <= 0 {
+		return b.Write(text)
+	}
+
+	r, ok := b.(Reader)
+	if !ok {
+		if err := b.Write(text); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s can't read the clipboard back", ErrNotCleared, b.Name())
+	}
+
+	job := restoreJob{Secret: text}
+	if prev, err := r.Read(); err == nil {
+		job.Previous, job.HadPrevious = prev, true
+	}
+	if err := b.Write(text); err != nil {
+		return err
+	}
+	if err := spawnRestore(b.Name(), job); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotCleared, err)
+	}
+	return nil
+}
+
+func spawnRestore(backend string, job restoreJob) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(exe, RestoreCommand, "--after", clearAfter.String(), "--backend", backend)
+	proc.Detach(cmd)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(stdin).Encode(job)
+	stdin.Close()
+	// Don't wait: the helper outlives us
+	_ = cmd.Process.Release()
+	return err
+}
+
+// RunRestore is the body of the helper process. It reads the job from in, waits,
+// and restores the previous contents only if the clipboard still holds the secret.
+func RunRestore(in io.Reader, after time.Duration, backend string) error {
+	var job restoreJob
+	if err := json.NewDecoder(in).Decode(&job); err != nil {
+		return err
+	}
+
+	b, ok := Get(backend)
+	if !ok {
+		return fmt.Errorf("unknown clipboard backend %q", backend)
+	}
+	r, ok := b.(Reader)
+	if !ok {
+		return fmt.Errorf("%s can't read the clipboard back", backend)
+	}
+
+	time.Sleep(after)
+
+	current, err := r.Read()
+	if err != nil {
+		return err
+	}
+	if current != job.Secret {
+		// The user copied something else since; leave it alone
+		return nil
+	}
+	if job.HadPrevious {
+		return b.Write(job.Previous)
+	}
+	return b.Write("")
+}
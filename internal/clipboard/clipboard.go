@@ -0,0 +1,153 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	system "github.com/atotto/clipboard"
+)
+
+// Backend names accepted in the `clipboard` config key
+const (
+	Auto   = "auto"
+	OSC52  = "osc52"
+	WlCopy = "wl-copy"
+	Xclip  = "xclip"
+	Xsel   = "xsel"
+	System = "system" // pbcopy on macOS, clip.exe on Windows
+	None   = "none"
+)
+
+// ErrDisabled is returned by the "none" backend so callers can stay quiet.
+var ErrDisabled = errors.New("clipboard disabled")
+
+// Backend writes text to a clipboard.
+type Backend interface {
+	Name() string
+	Available() bool
+	Write(text string) error
+}
+
+// Reader is implemented by backends that can also read the clipboard back.
+// OSC 52 can't, so auto-clearing is not possible there.
+type Reader interface {
+	Read() (string, error)
+}
+
+var backends = map[string]Backend{
+	OSC52:  osc52{},
+	WlCopy: command{name: WlCopy, args: []string{"wl-copy"}, readArgs: []string{"wl-paste", "--no-newline"}},
+	Xclip:  command{name: Xclip, args: []string{"xclip", "-selection", "clipboard"}, readArgs: []string{"xclip", "-selection", "clipboard", "-o"}},
+	Xsel:   command{name: Xsel, args: []string{"xsel", "--clipboard", "--input"}, readArgs: []string{"xsel", "--clipboard", "--output"}},
+	System: systemBackend{},
+	None:   none{},
+}
+
+var active Backend
+
+// Use selects the backend by name; "" and "auto" detect one from the environment.
+func Use(name string) error {
+	if name == "" || name == Auto {
+		active = Detect()
+		return nil
+	}
+	b, ok := backends[name]
+	if !ok {
+		active = Detect()
+		return fmt.Errorf("unknown clipboard backend %q (use auto, osc52, wl-copy, xclip, xsel, system or none)", name)
+	}
+	active = b
+	return nil
+}
+
+// Get returns the backend registered under name.
+func Get(name string) (Backend, bool) {
+	b, ok := backends[name]
+	return b, ok
+}
+
+// Current returns the selected backend, detecting one if Use was never called.
+func Current() Backend {
+	if active == nil {
+		active = Detect()
+	}
+	return active
+}
+
+// Write puts text on the current clipboard.
+func Write(text string) error {
+	return Current().Write(text)
+}
+
+// Detect picks a backend: OSC 52 over SSH (the local terminal owns the clipboard),
+// then Wayland, then X11, then the OS clipboard, then OSC 52 again inside tmux.
+func Detect() Backend {
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		return backends[OSC52]
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" && backends[WlCopy].Available() {
+		return backends[WlCopy]
+	}
+	if os.Getenv("DISPLAY") != "" {
+		for _, name := range []string{Xclip, Xsel} {
+			if backends[name].Available() {
+				return backends[name]
+			}
+		}
+	}
+	if runtime.GOOS != "linux" && backends[System].Available() {
+		return backends[System]
+	}
+	if os.Getenv("TMUX") != "" || backends[OSC52].Available() {
+		return backends[OSC52]
+	}
+	return backends[None]
+}
+
+// command pipes the text into a clipboard CLI.
+type command struct {
+	name     string
+	args     []string
+	readArgs []string
+}
+
+func (c command) Name() string { return c.name }
+
+func (c command) Available() bool {
+	_, err := exec.LookPath(c.args[0])
+	return err == nil
+}
+
+func (c command) Write(text string) error {
+	cmd := exec.Command(c.args[0], c.args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v %s", c.name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (c command) Read() (string, error) {
+	out, err := exec.Command(c.readArgs[0], c.readArgs[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", c.readArgs[0], err)
+	}
+	return string(out), nil
+}
+
+type systemBackend struct{}
+
+func (systemBackend) Name() string            { return System }
+func (systemBackend) Available() bool         { return !system.Unsupported }
+func (systemBackend) Write(text string) error { return system.WriteAll(text) }
+func (systemBackend) Read() (string, error)   { return system.ReadAll() }
+
+type none struct{}
+
+func (none) Name() string            { return None }
+func (none) Available() bool         { return true }
+func (none) Write(text string) error { return ErrDisabled }
@@ -0,0 +1,45 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// osc52 asks the terminal emulator to set the clipboard with an escape sequence.
+// It works through SSH and tmux as long as the terminal supports OSC 52.
+type osc52 struct{}
+
+func (osc52) Name() string { return OSC52 }
+
+func (osc52) Available() bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+func (osc52) Write(text string) error {
+	// Write to the terminal directly so it works even when stdout is piped (--print)
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("osc52: no terminal: %w", err)
+	}
+	defer tty.Close()
+
+	_, err = tty.WriteString(osc52Sequence(text, os.Getenv("TMUX") != ""))
+	return err
+}
+
+func osc52Sequence(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if tmux {
+		// DCS passthrough: tmux forwards the sequence to the outer terminal.
+		// Needs `set -g allow-passthrough on` on tmux >= 3.3.
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
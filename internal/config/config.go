@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -16,14 +18,152 @@ type Environment struct {
 	PrometheusUID string `mapstructure:"prometheus_uid" yaml:"prometheus_uid"`
 	Username      string `mapstructure:"username"       yaml:"username"`
 	Password      string `mapstructure:"password"       yaml:"password"`
+	// PasswordRef points at the password instead of storing it, e.g. "keyring:grafana-connect/prod",
+	// "exec:pass show grafana/prod", "env:GRAFANA_PROD_PASS" or "file:~/.secrets/grafana-prod".
+	PasswordRef string `mapstructure:"password_ref" yaml:"password_ref,omitempty"`
+	// Token is a Grafana service-account token for API calls. Used instead of basic auth when set.
+	Token    string `mapstructure:"token"     yaml:"token,omitempty"`
+	TokenRef string `mapstructure:"token_ref" yaml:"token_ref,omitempty"` // same syntax as password_ref
+	// Priority breaks ties when several environments match the same context. Higher wins.
+	Priority int `mapstructure:"priority" yaml:"priority,omitempty"`
+	// Match adds optional regexes on the kubeconfig entry itself, so the env is found
+	// no matter what each user named their context.
+	Match *Match `mapstructure:"match" yaml:"match,omitempty"`
+	// Dashboards are the env's named dashboards, on top of the global catalog.
+	Dashboards       map[string]Dashboard `mapstructure:"dashboards"        yaml:"dashboards,omitempty"`
+	DefaultDashboard string               `mapstructure:"default_dashboard" yaml:"default_dashboard,omitempty"`
+	// From/To are the default time range for every dashboard of the env.
+	From string `mapstructure:"from" yaml:"from,omitempty"`
+	To   string `mapstructure:"to"   yaml:"to,omitempty"`
+	// LokiUID is the Loki datasource used by `explore` for LogQL queries.
+	LokiUID string `mapstructure:"loki_uid" yaml:"loki_uid,omitempty"`
+	// Queries are the env's named Explore queries, on top of the global ones.
+	Queries map[string]Query `mapstructure:"queries" yaml:"queries,omitempty"`
+	// ExploreFormat is "panes" (Grafana 10+, default) or "left" for older instances.
+	ExploreFormat string `mapstructure:"explore_format" yaml:"explore_format,omitempty"`
+	// OrgID, Refresh and Vars apply to the env's dashboards that don't set their own.
+	OrgID   int                  `mapstructure:"org_id"  yaml:"org_id,omitempty"`
+	Refresh string               `mapstructure:"refresh" yaml:"refresh,omitempty"`
+	Vars    map[string]VarValues `mapstructure:"vars"    yaml:"vars,omitempty"`
+	// Namespaces are offered by the pickers when the cluster won't list its namespaces.
+	Namespaces []string `mapstructure:"namespaces" yaml:"namespaces,omitempty"`
+}
+
+// Match modes
+const (
+	MatchModeAll = "all" // every set matcher (and context_match) must match
+	MatchModeAny = "any" // one hit is enough
+)
+
+// Match holds regexes checked against the cluster and user of a kubeconfig context.
+type Match struct {
+	Mode      string `mapstructure:"mode"      yaml:"mode,omitempty"`
+	Server    string `mapstructure:"server"    yaml:"server,omitempty"`    // cluster API server URL
+	Cluster   string `mapstructure:"cluster"   yaml:"cluster,omitempty"`   // kubeconfig cluster name
+	User      string `mapstructure:"user"      yaml:"user,omitempty"`      // kubeconfig auth-info name
+	Namespace string `mapstructure:"namespace" yaml:"namespace,omitempty"` // default namespace of the context
 }
 
 type Config struct {
-	// Global defaults are gone. Only the list remains.
+	// Include pulls environments, dashboards, queries and defaults from other files,
+	// directories or globs (e.g. a team repo). Entries in this file override them by name.
+	Include []string `mapstructure:"include" yaml:"include,omitempty"`
+	// Defaults fill in whatever an environment leaves unset (see Effective).
+	Defaults     Defaults      `mapstructure:"defaults"     yaml:"defaults,omitempty"`
 	Environments []Environment `mapstructure:"environments" yaml:"environments"`
+	// Dashboards is a catalog of named dashboards every environment inherits.
+	Dashboards map[string]Dashboard `mapstructure:"dashboards" yaml:"dashboards,omitempty"`
+	// Queries is a catalog of named Explore queries every environment inherits.
+	Queries map[string]Query `mapstructure:"queries" yaml:"queries,omitempty"`
+	// Clipboard forces a clipboard backend: auto (default), osc52, wl-copy, xclip, xsel, system or none.
+	Clipboard string `mapstructure:"clipboard" yaml:"clipboard,omitempty"`
+	// ClipboardClearAfter restores the previous clipboard contents this long after
+	// copying a password (e.g. "30s"). Empty keeps the password on the clipboard.
+	ClipboardClearAfter string `mapstructure:"clipboard_clear_after" yaml:"clipboard_clear_after,omitempty"`
+	// NamespaceCacheTTL is how long listed namespaces are served from the cache without
+	// a refresh (e.g. "10m"). Empty means 5m, "0" turns the cache off.
+	NamespaceCacheTTL string `mapstructure:"namespace_cache_ttl" yaml:"namespace_cache_ttl,omitempty"`
+	// DefaultDashboard is the old spelling of defaults.dashboard, still honored.
+	DefaultDashboard string `mapstructure:"default_dashboard" yaml:"default_dashboard,omitempty"`
+
+	// Files are the files the config was read from, the main one first. Set by LoadConfig.
+	Files []string `mapstructure:"-" yaml:"-"`
+	// Sources maps each environment name to where it was defined. Set by LoadConfig.
+	Sources map[string]Source `mapstructure:"-" yaml:"-"`
+}
+
+// warn receives the problems LoadConfig can go on with. See OnWarning.
+var (
+	warn   = func(Problem) {}
+	warned = map[Problem]bool{}
+)
+
+// OnWarning sets what LoadConfig does with problems it can load the config despite,
+// such as unknown keys. Each one is passed once per process.
+func OnWarning(f func(Problem)) {
+	warn = f
+}
+
+func reportWarnings(problems []Problem) {
+	for _, p := range problems {
+		if !warned[p] {
+			warned[p] = true
+			warn(p)
+		}
+	}
+}
+
+// checkLoadable fails on the problems that make the config at path unusable and
+// reports the rest as warnings.
+func checkLoadable(path string, problems []Problem) error {
+	errs, warnings := SplitWarnings(problems)
+	if len(errs) > 0 {
+		return &ValidationError{File: path, Problems: errs}
+	}
+	reportWarnings(warnings)
+	return nil
 }
 
 func LoadConfig() (*Config, error) {
+	if _, err := FindConfigFile(); err != nil {
+		return nil, err
+	}
+	// viper drops unknown keys silently, so check the file itself first
+	if err := ValidateFile(viper.ConfigFileUsed()); err != nil {
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) {
+			return nil, err
+		}
+		if err := checkLoadable(invalid.File, invalid.Problems); err != nil {
+			return nil, err
+		}
+	}
+
+	var cfg Config
+	err := viper.Unmarshal(&cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		dashboardDecodeHook,
+		// viper's defaults
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Conflicts between included files are left to `config validate`; the first definition wins
+	problems, _, err := cfg.resolveIncludes(viper.ConfigFileUsed(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLoadable(viper.ConfigFileUsed(), problems); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// FindConfigFile looks for config.yaml in ~/.config/grafana-connect, then the current
+// directory, reads it and returns its path.
+func FindConfigFile() (string, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 
@@ -32,12 +172,14 @@ func LoadConfig() (*Config, error) {
 	viper.AddConfigPath(".")
 
 	if err := viper.ReadInConfig(); err != nil {
-		return nil, err
+		return "", err
 	}
+	return viper.ConfigFileUsed(), nil
+}
 
-	var cfg Config
-	err := viper.Unmarshal(&cfg)
-	return &cfg, err
+// ConfigFileUsed returns the path LoadConfig read, or "" if it hasn't found one.
+func ConfigFileUsed() string {
+	return viper.ConfigFileUsed()
 }
 
 // Helper to find env by Alias
@@ -49,3 +191,13 @@ func (c *Config) FindByAlias(alias string) *Environment {
 	}
 	return nil
 }
+
+// FindByName finds an environment by its name.
+func (c *Config) FindByName(name string) *Environment {
+	for _, env := range c.Environments {
+		if env.Name == name {
+			return &env
+		}
+	}
+	return nil
+}
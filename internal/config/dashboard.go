@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dashboard is a named dashboard. In YAML it can be written as just its path.
+type Dashboard struct {
+	Path    string `mapstructure:"path"    yaml:"path"` // "uid/slug"
+	OrgID   int    `mapstructure:"org_id"  yaml:"org_id,omitempty"`
+	Refresh string `mapstructure:"refresh" yaml:"refresh,omitempty"`
+	// From/To are the default time range (e.g. "now-6h" / "now"), overriding the env's.
+	From string `mapstructure:"from" yaml:"from,omitempty"`
+	To   string `mapstructure:"to"   yaml:"to,omitempty"`
+	// Vars are the dashboard variables sent as var-<name>. Values are Go templates
+	// rendered against the launch target ({{ .Namespace }}, {{ .Env.PrometheusUID }}, ...).
+	// When set they replace the built-in variables entirely; values rendering to "" are dropped.
+	Vars map[string]VarValues `mapstructure:"vars" yaml:"vars,omitempty"`
+}
+
+// VarValues holds one or more values for a dashboard variable. In YAML it can be a
+// single string or a list (for multi-value variables).
+type VarValues []string
+
+func (v *VarValues) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*v = VarValues{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*v = list
+	return nil
+}
+
+func (v VarValues) MarshalYAML() (any, error) {
+	if len(v) == 1 {
+		return v[0], nil
+	}
+	return []string(v), nil
+}
+
+// UnmarshalYAML accepts both `pods: "uid/slug"` and `pods: {path: "uid/slug"}`.
+func (d *Dashboard) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		d.Path = value.Value
+		return nil
+	}
+	type plain Dashboard
+	return value.Decode((*plain)(d))
+}
+
+// MarshalYAML writes the short form when only the path is set.
+func (d Dashboard) MarshalYAML() (any, error) {
+	if reflect.DeepEqual(d, Dashboard{Path: d.Path}) {
+		return d.Path, nil
+	}
+	type plain Dashboard
+	return plain(d), nil
+}
+
+// dashboardDecodeHook lets viper decode the short string forms of Dashboard, VarValues and Query.
+func dashboardDecodeHook(from, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	switch to {
+	case reflect.TypeOf(Dashboard{}):
+		return Dashboard{Path: data.(string)}, nil
+	case reflect.TypeOf(VarValues{}):
+		return VarValues{data.(string)}, nil
+	case reflect.TypeOf(Query{}):
+		return Query{Expr: data.(string)}, nil
+	}
+	return data, nil
+}
+
+// DashboardsFor merges the global catalog with the env's own dashboards (env entries win).
+// The legacy single `dashboard` field shows up as "default" unless that name is taken.
+func (c *Config) DashboardsFor(env *Environment) map[string]Dashboard {
+	out := make(map[string]Dashboard, len(c.Dashboards)+len(env.Dashboards)+1)
+	for name, d := range c.Dashboards {
+		out[name] = d
+	}
+	for name, d := range env.Dashboards {
+		out[name] = d
+	}
+	if _, taken := out["default"]; !taken && env.Dashboard != "" {
+		out["default"] = Dashboard{Path: env.Dashboard}
+	}
+	return out
+}
+
+// DashboardNames returns the names from DashboardsFor, sorted.
+func (c *Config) DashboardNames(env *Environment) []string {
+	all := c.DashboardsFor(env)
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultDashboardName is the dashboard opened when none is requested:
+// default_dashboard, then "default", then the only entry if there is exactly one.
+func (c *Config) DefaultDashboardName(env *Environment) string {
+	if env.DefaultDashboard != "" {
+		return env.DefaultDashboard
+	}
+	all := c.DashboardsFor(env)
+	if _, ok := all["default"]; ok {
+		return "default"
+	}
+	if len(all) == 1 {
+		for name := range all {
+			return name
+		}
+	}
+	return ""
+}
+
+// ResolveDashboard looks up a dashboard by name for env. An empty name means the default.
+func (c *Config) ResolveDashboard(env *Environment, name string) (Dashboard, error) {
+	if name == "" {
+		name = c.DefaultDashboardName(env)
+		if name == "" {
+			// None of the dashboards is the default; Effective fills this in from defaults.dashboard
+			return Dashboard{Path: env.Dashboard}, nil
+		}
+	}
+	d, ok := c.DashboardsFor(env)[name]
+	if !ok {
+		return Dashboard{}, fmt.Errorf("no dashboard named %q for %s (available: %s)",
+			name, env.Name, strings.Join(c.DashboardNames(env), ", "))
+	}
+	return d, nil
+}
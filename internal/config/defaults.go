@@ -0,0 +1,73 @@
+package config
+
+import "cmp"
+
+// Defaults apply to every environment that doesn't set the value itself.
+type Defaults struct {
+	// Dashboard is the path ("uid/slug") opened for environments without any dashboard.
+	Dashboard string `mapstructure:"dashboard" yaml:"dashboard,omitempty"`
+	Username  string `mapstructure:"username"  yaml:"username,omitempty"`
+	OrgID     int    `mapstructure:"org_id"    yaml:"org_id,omitempty"`
+	Refresh   string `mapstructure:"refresh"   yaml:"refresh,omitempty"`
+	From      string `mapstructure:"from"      yaml:"from,omitempty"`
+	To        string `mapstructure:"to"        yaml:"to,omitempty"`
+	// Vars are the dashboard variables used when neither the dashboard nor the env sets any.
+	Vars map[string]VarValues `mapstructure:"vars" yaml:"vars,omitempty"`
+	// Clipboard and ClipboardClearAfter are used when the top-level keys aren't set.
+	Clipboard           string `mapstructure:"clipboard"             yaml:"clipboard,omitempty"`
+	ClipboardClearAfter string `mapstructure:"clipboard_clear_after" yaml:"clipboard_clear_after,omitempty"`
+}
+
+// BuiltinDefaults are used for whatever the config's defaults block leaves out.
+// The dashboard and its variables are those of the Kubernetes pod-resources dashboard.
+var BuiltinDefaults = Defaults{
+	Dashboard: "k8s-pod-resources/kubernetes-pod-resource-dashboard",
+	OrgID:     1,
+	Refresh:   "30s",
+	Vars: map[string]VarValues{
+		"DS_PROMETHEUS": {"{{ .Env.PrometheusUID }}"},
+		"namespace":     {"{{ .Namespace }}"},
+		"deployment":    {"{{ .Deployment }}"},
+		"pod":           {"{{ .Pod }}"},
+	},
+}
+
+// EffectiveDefaults is the defaults block with the built-in values filled in.
+func (c *Config) EffectiveDefaults() Defaults {
+	d, b := c.Defaults, BuiltinDefaults
+	d.Dashboard = cmp.Or(d.Dashboard, c.DefaultDashboard, b.Dashboard)
+	d.Username = cmp.Or(d.Username, b.Username)
+	if d.OrgID == 0 {
+		d.OrgID = b.OrgID
+	}
+	d.Refresh = cmp.Or(d.Refresh, b.Refresh)
+	d.From = cmp.Or(d.From, b.From)
+	d.To = cmp.Or(d.To, b.To)
+	if d.Vars == nil {
+		d.Vars = b.Vars
+	}
+	d.Clipboard = cmp.Or(c.Clipboard, d.Clipboard, b.Clipboard)
+	d.ClipboardClearAfter = cmp.Or(c.ClipboardClearAfter, d.ClipboardClearAfter, b.ClipboardClearAfter)
+	return d
+}
+
+// Effective returns env with every unset value taken from the defaults. The default
+// dashboard applies to environments none of whose dashboards is the default one.
+func (c *Config) Effective(env *Environment) Environment {
+	d := c.EffectiveDefaults()
+	e := *env
+	e.Username = cmp.Or(e.Username, d.Username)
+	if e.OrgID == 0 {
+		e.OrgID = d.OrgID
+	}
+	e.Refresh = cmp.Or(e.Refresh, d.Refresh)
+	e.From = cmp.Or(e.From, d.From)
+	e.To = cmp.Or(e.To, d.To)
+	if e.Vars == nil {
+		e.Vars = d.Vars
+	}
+	if c.DefaultDashboardName(env) == "" {
+		e.Dashboard = d.Dashboard
+	}
+	return e
+}
@@ -0,0 +1,369 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a config file held as a YAML node tree. Edits go through the tree,
+// so comments, key order and formatting of untouched entries survive a save.
+type Document struct {
+	Path string
+	doc  *yaml.Node
+	src  []byte // the file as read, to restore its blank lines and indentation
+}
+
+// DefaultConfigPath is where a new config file is created.
+func DefaultConfigPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "grafana-connect", "config.yaml")
+}
+
+// OpenDocument reads the config at path. A missing file gives an empty document.
+func OpenDocument(path string) (*Document, error) {
+	d := &Document{Path: path, doc: &yaml.Node{Kind: yaml.DocumentNode}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, d.doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	d.src = data
+	if d.doc.Kind == 0 {
+		d.doc.Kind = yaml.DocumentNode // empty file
+	}
+	return d, nil
+}
+
+// Decode returns the config the document currently holds.
+func (d *Document) Decode() (*Config, error) {
+	var cfg Config
+	if len(d.doc.Content) == 0 {
+		return &cfg, nil
+	}
+	err := d.doc.Content[0].Decode(&cfg)
+	return &cfg, err
+}
+
+// root returns the top-level mapping, creating it in an empty document.
+func (d *Document) root() *yaml.Node {
+	if len(d.doc.Content) == 0 {
+		d.doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	return d.doc.Content[0]
+}
+
+// environments returns the environments sequence, or nil. With create it is added if missing.
+func (d *Document) environments(create bool) *yaml.Node {
+	list := mappingValue(d.root(), "environments")
+	if list == nil || (list.Kind == yaml.ScalarNode && list.Tag == "!!null") {
+		if !create {
+			return nil
+		}
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+		setMappingValue(d.root(), "environments", list)
+	}
+	return list
+}
+
+// findEnv returns the index of the environment named ref, or else the one with alias ref.
+func (d *Document) findEnv(ref string) int {
+	list := d.environments(false)
+	if list == nil {
+		return -1
+	}
+	for _, key := range []string{"name", "alias"} {
+		for i, n := range list.Content {
+			if v := mappingValue(n, key); v != nil && v.Value == ref {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// HasEnv reports whether an environment is named or aliased ref.
+func (d *Document) HasEnv(ref string) bool {
+	return d.findEnv(ref) >= 0
+}
+
+// EnvIndex returns the index of the environment found by name or alias, or -1.
+func (d *Document) EnvIndex(ref string) int {
+	return d.findEnv(ref)
+}
+
+// EnvName returns the name of the environment found by name or alias.
+func (d *Document) EnvName(ref string) string {
+	i := d.findEnv(ref)
+	if i < 0 {
+		return ""
+	}
+	if n := mappingValue(d.environments(false).Content[i], "name"); n != nil {
+		return n.Value
+	}
+	return ref
+}
+
+// AddEnv appends a new environment with only its name set and returns its index.
+func (d *Document) AddEnv(name string) (int, error) {
+	if d.HasEnv(name) {
+		return -1, fmt.Errorf("environment %q already exists", name)
+	}
+	env := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(env, "name", stringNode(name))
+	list := d.environments(true)
+	list.Content = append(list.Content, env)
+	return len(list.Content) - 1, nil
+}
+
+// RemoveEnv deletes the environment found by name or alias.
+func (d *Document) RemoveEnv(ref string) error {
+	i := d.findEnv(ref)
+	if i < 0 {
+		return fmt.Errorf("no environment named %q", ref)
+	}
+	list := d.environments(false)
+	list.Content = append(list.Content[:i], list.Content[i+1:]...)
+	return nil
+}
+
+// EnvFields lists the paths of the settable scalar fields of an environment,
+// e.g. "base_url" or "match.server".
+func EnvFields() []string {
+	return scalarPaths(reflect.TypeOf(Environment{}), "")
+}
+
+func scalarPaths(t reflect.Type, prefix string) []string {
+	var out []string
+	keys, fields := yamlFields(t)
+	for _, key := range keys {
+		ft := fields[key].Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.String, reflect.Int:
+			out = append(out, prefix+key)
+		case reflect.Slice:
+			if ft.Elem().Kind() == reflect.String {
+				out = append(out, prefix+key) // comma-separated
+			}
+		case reflect.Struct:
+			out = append(out, scalarPaths(ft, prefix+key+".")...)
+		}
+	}
+	return out
+}
+
+// shortFormKey is the field the plain string form of a type stands for.
+var shortFormKey = map[reflect.Type]string{
+	reflect.TypeOf(Dashboard{}): "path",
+	reflect.TypeOf(Query{}):     "expr",
+}
+
+// Set sets the value at a dotted path such as "clipboard", "environments.prod.dashboard"
+// or "dashboards.pods.org_id". Environments are addressed by name or alias. Missing
+// intermediate mappings are created. An empty value removes the key.
+func (d *Document) Set(path, value string) error {
+	return d.setIn(d.root(), reflect.TypeOf(Config{}), path, value)
+}
+
+// SetEnv sets a field of the i-th environment, like Set with "environments.<env>.<field>".
+func (d *Document) SetEnv(i int, field, value string) error {
+	list := d.environments(false)
+	if list == nil || i < 0 || i >= len(list.Content) {
+		return fmt.Errorf("no environment #%d", i)
+	}
+	return d.setIn(list.Content[i], reflect.TypeOf(Environment{}), field, value)
+}
+
+// setIn sets path relative to parent, a mapping holding a value of type t.
+func (d *Document) setIn(parent *yaml.Node, t reflect.Type, path, value string) error {
+	segments := strings.Split(path, ".")
+	if value == "" {
+		return d.unset(parent, t, segments)
+	}
+
+	for i, seg := range segments[:len(segments)-1] {
+		child, ct, err := d.descend(parent, t, seg, strings.Join(segments[:i+1], "."))
+		if err != nil {
+			return err
+		}
+		parent, t = child, ct
+	}
+
+	key := segments[len(segments)-1]
+	ft, err := childType(t, key, path)
+	if err != nil {
+		return err
+	}
+	n, err := valueNode(ft, value, path)
+	if err != nil {
+		return err
+	}
+	if old := mappingValue(parent, key); old != nil {
+		n.HeadComment, n.LineComment, n.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		if old.Kind == yaml.ScalarNode && n.Kind == yaml.ScalarNode {
+			n.Style = old.Style // keep the quoting
+		}
+	}
+	setMappingValue(parent, key, n)
+	return nil
+}
+
+func (d *Document) unset(parent *yaml.Node, t reflect.Type, segments []string) error {
+	for _, seg := range segments[:len(segments)-1] {
+		if t.Kind() == reflect.Slice {
+			i := d.findEnv(seg)
+			if i < 0 {
+				return fmt.Errorf("no environment named %q", seg)
+			}
+			parent, t = parent.Content[i], t.Elem()
+			continue
+		}
+		next := mappingValue(parent, seg)
+		ct, err := childType(t, seg, strings.Join(segments, "."))
+		if err != nil {
+			return err
+		}
+		if next == nil || next.Kind == yaml.ScalarNode {
+			return nil // nothing to remove
+		}
+		parent, t = next, ct
+	}
+	deleteMappingKey(parent, segments[len(segments)-1])
+	return nil
+}
+
+// descend steps from a mapping (of type t) into key seg, creating it if missing.
+func (d *Document) descend(parent *yaml.Node, t reflect.Type, seg, path string) (*yaml.Node, reflect.Type, error) {
+	if t.Kind() == reflect.Slice {
+		if t.Elem() != reflect.TypeOf(Environment{}) {
+			return nil, nil, fmt.Errorf("%s: can't set list items, set the whole list instead", path)
+		}
+		i := d.findEnv(seg)
+		if i < 0 {
+			return nil, nil, fmt.Errorf("no environment named %q", seg)
+		}
+		return parent.Content[i], t.Elem(), nil
+	}
+
+	ct, err := childType(t, seg, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch ct.Kind() {
+	case reflect.Struct, reflect.Map:
+	case reflect.Slice:
+		if seg == "environments" {
+			list := d.environments(false)
+			if list == nil {
+				return nil, nil, fmt.Errorf("no environments defined")
+			}
+			return list, ct, nil
+		}
+		return nil, nil, fmt.Errorf("%s is a list, set it as a whole", path)
+	default:
+		return nil, nil, fmt.Errorf("%s is not a mapping", path)
+	}
+
+	child := mappingValue(parent, seg)
+	switch {
+	case child == nil || (child.Kind == yaml.ScalarNode && child.Tag == "!!null"):
+		child = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(parent, seg, child)
+	case child.Kind == yaml.ScalarNode && shortFormKey[ct] != "":
+		// Expand "pods: uid/slug" to "pods: {path: uid/slug}" to set a sibling field
+		short := stringNode(child.Value)
+		*child = yaml.Node{Kind: yaml.MappingNode, HeadComment: child.HeadComment, LineComment: child.LineComment}
+		setMappingValue(child, shortFormKey[ct], short)
+	case child.Kind != yaml.MappingNode:
+		return nil, nil, fmt.Errorf("%s is not a mapping", path)
+	}
+	return child, ct, nil
+}
+
+// childType is the Go type of key inside a value of type t.
+func childType(t reflect.Type, key, path string) (reflect.Type, error) {
+	var ct reflect.Type
+	switch t.Kind() {
+	case reflect.Map:
+		ct = t.Elem()
+	case reflect.Struct:
+		keys, fields := yamlFields(t)
+		f, ok := fields[key]
+		if !ok {
+			if hint := closest(key, keys); hint != "" {
+				return nil, fmt.Errorf("%s: unknown key %q (did you mean %q?)", path, key, hint)
+			}
+			return nil, fmt.Errorf("%s: unknown key %q", path, key)
+		}
+		ct = f.Type
+	default:
+		return nil, fmt.Errorf("%s: %q has no keys", path, key)
+	}
+	for ct.Kind() == reflect.Pointer {
+		ct = ct.Elem()
+	}
+	return ct, nil
+}
+
+// valueNode builds the node for a value of type t from its command-line form.
+// Lists are comma separated.
+func valueNode(t reflect.Type, value, path string) (*yaml.Node, error) {
+	switch {
+	case t.Kind() == reflect.Int:
+		if _, err := strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%s: %q is not an integer", path, value)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value}, nil
+	case t.Kind() == reflect.String, hasShortForm(t) && t.Kind() != reflect.Slice:
+		return stringNode(value), nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		parts := strings.Split(value, ",")
+		if len(parts) == 1 && hasShortForm(t) {
+			return stringNode(value), nil
+		}
+		list := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, p := range parts {
+			list.Content = append(list.Content, stringNode(strings.TrimSpace(p)))
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("%s is a mapping, set one of its keys (%s.<key>)", path, path)
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// setMappingValue replaces the value of key in a mapping node, or appends the pair.
+func setMappingValue(n *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content[i+1] = value
+			return
+		}
+	}
+	n.Content = append(n.Content, stringNode(key), value)
+}
+
+func deleteMappingKey(n *yaml.Node, key string) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			return
+		}
+	}
+}
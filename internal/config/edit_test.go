@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// editFixture has comments, blank lines, a short-form dashboard and a 4-space indent.
+const editFixture = `# Grafana environments
+clipboard: osc52 # over SSH
+
+dashboards:
+    # the one everybody uses
+    pods: "k8s/pods"
+
+environments:
+
+    # development
+    - name: dev.eu
+      alias: d
+      base_url: https://dev.example.com # VPN only
+      priority: 1
+
+    - name: prod.eu
+      alias: p
+      base_url: https://prod.example.com
+`
+
+// openFixture writes content to a temp config and opens it.
+func openFixture(t *testing.T, content string) *Document {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := OpenDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// assertBytes checks the rendered document against want.
+func assertBytes(t *testing.T, d *Document, want string) {
+	t.Helper()
+	got, err := d.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDocumentUntouched(t *testing.T) {
+	assertBytes(t, openFixture(t, editFixture), editFixture)
+}
+
+func TestDocumentSet(t *testing.T) {
+	d := openFixture(t, editFixture)
+	for path, value := range map[string]string{
+		"clipboard":               "none",
+		"environments.p.priority": "5",   // by alias
+		"namespace_cache_ttl":     "10m", // new keys go last
+	} {
+		if err := d.Set(path, value); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	assertBytes(t, d, `# Grafana environments
+clipboard: none # over SSH
+
+dashboards:
+    # the one everybody uses
+    pods: "k8s/pods"
+
+environments:
+
+    # development
+    - name: dev.eu
+      alias: d
+      base_url: https://dev.example.com # VPN only
+      priority: 1
+
+    - name: prod.eu
+      alias: p
+      base_url: https://prod.example.com
+      priority: 5
+namespace_cache_ttl: 10m
+`)
+}
+
+func TestDocumentSetErrors(t *testing.T) {
+	d := openFixture(t, editFixture)
+	tests := map[string]string{
+		"clipbaord":                     `clipbaord: unknown key "clipbaord" (did you mean "clipboard"?)`,
+		"environments.staging.priority": `no environment named "staging"`,
+		"environments.d.priority":       `environments.d.priority: "high" is not an integer`,
+		"clipboard.mode":                `clipboard is not a mapping`,
+	}
+	for path, want := range tests {
+		if err := d.Set(path, "high"); err == nil || err.Error() != want {
+			t.Errorf("%s: got %v, want %q", path, err, want)
+		}
+	}
+}
+
+func TestDocumentSetEnv(t *testing.T) {
+	d := openFixture(t, editFixture)
+	// By index, so the dots in the names don't matter
+	steps := []struct {
+		i            int
+		field, value string
+	}{
+		{0, "base_url", "https://dev2.example.com"}, // keeps the line comment
+		{0, "priority", ""},                         // removes the key
+		{1, "match.server", "prod"},                 // creates the mapping
+	}
+	for _, s := range steps {
+		if err := d.SetEnv(s.i, s.field, s.value); err != nil {
+			t.Fatalf("%s: %v", s.field, err)
+		}
+	}
+	if err := d.SetEnv(2, "alias", "x"); err == nil {
+		t.Error("expected an error for a missing environment")
+	}
+	assertBytes(t, d, `# Grafana environments
+clipboard: osc52 # over SSH
+
+dashboards:
+    # the one everybody uses
+    pods: "k8s/pods"
+
+environments:
+
+    # development
+    - name: dev.eu
+      alias: d
+      base_url: https://dev2.example.com # VPN only
+
+    - name: prod.eu
+      alias: p
+      base_url: https://prod.example.com
+      match:
+        server: prod
+`)
+}
+
+func TestDocumentExpandShortForm(t *testing.T) {
+	d := openFixture(t, editFixture)
+	if err := d.Set("dashboards.pods.org_id", "2"); err != nil {
+		t.Fatal(err)
+	}
+	assertBytes(t, d, `# Grafana environments
+clipboard: osc52 # over SSH
+
+dashboards:
+    # the one everybody uses
+    pods:
+        path: k8s/pods
+        org_id: 2
+
+environments:
+
+    # development
+    - name: dev.eu
+      alias: d
+      base_url: https://dev.example.com # VPN only
+      priority: 1
+
+    - name: prod.eu
+      alias: p
+      base_url: https://prod.example.com
+`)
+	cfg, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Dashboards["pods"]; got.Path != "k8s/pods" || got.OrgID != 2 {
+		t.Errorf("decoded %+v", got)
+	}
+}
+
+func TestDocumentRemoveEnv(t *testing.T) {
+	// The first environment takes its comment along, and no blank line is left after the key
+	d := openFixture(t, editFixture)
+	if err := d.RemoveEnv("d"); err != nil {
+		t.Fatal(err)
+	}
+	assertBytes(t, d, `# Grafana environments
+clipboard: osc52 # over SSH
+
+dashboards:
+    # the one everybody uses
+    pods: "k8s/pods"
+
+environments:
+    - name: prod.eu
+      alias: p
+      base_url: https://prod.example.com
+`)
+
+	d = openFixture(t, editFixture)
+	if err := d.RemoveEnv("prod.eu"); err != nil {
+		t.Fatal(err)
+	}
+	assertBytes(t, d, `# Grafana environments
+clipboard: osc52 # over SSH
+
+dashboards:
+    # the one everybody uses
+    pods: "k8s/pods"
+
+environments:
+
+    # development
+    - name: dev.eu
+      alias: d
+      base_url: https://dev.example.com # VPN only
+      priority: 1
+`)
+
+	if err := d.RemoveEnv("prod.eu"); err == nil {
+		t.Error("expected an error for a missing environment")
+	}
+}
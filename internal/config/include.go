@@ -0,0 +1,344 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source is where an environment was defined.
+type Source struct {
+	File string
+	// Overrides is the included file whose entry of the same name this one replaces.
+	Overrides string
+}
+
+// fragmentKeys are the top-level keys read from included files.
+var fragmentKeys = []string{"environments", "dashboards", "queries", "defaults"}
+
+// fragment is an included file.
+type fragment struct {
+	file string
+	cfg  Config
+	root *yaml.Node // top-level mapping, to locate conflicts
+}
+
+// IncludedFiles expands include patterns: files, directories (their *.yaml and *.yml
+// files) and globs. Relative patterns are relative to the directory of the config at path.
+func IncludedFiles(path string, patterns []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+
+	for _, pattern := range patterns {
+		p := expandHome(pattern)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(path), p)
+		}
+
+		if strings.ContainsAny(p, "*?[") {
+			matches, err := filepath.Glob(p)
+			if err != nil {
+				return nil, fmt.Errorf("include %q: %w", pattern, err)
+			}
+			sort.Strings(matches)
+			for _, m := range matches {
+				add(m)
+			}
+			continue
+		}
+
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		if !info.IsDir() {
+			add(p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		for _, e := range entries {
+			if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				add(filepath.Join(p, e.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, p[1:])
+	}
+	return p
+}
+
+// loadFragment reads and validates an included file.
+func loadFragment(file string) (*fragment, []Problem, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems, err := Validate(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	f := &fragment{file: file, root: &yaml.Node{Kind: yaml.MappingNode}}
+	if len(doc.Content) > 0 {
+		f.root = doc.Content[0]
+	}
+	if errs, _ := SplitWarnings(problems); len(errs) == 0 {
+		if err := f.root.Decode(&f.cfg); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	// Personal settings only come from the main file
+	for i := 0; i+1 < len(f.root.Content); i += 2 {
+		k := f.root.Content[i]
+		if !slices.Contains(fragmentKeys, k.Value) && !isMergeKey(k) {
+			problems = append(problems, Problem{Line: k.Line, Column: k.Column, Warning: true,
+				Message: fmt.Sprintf("%q isn't allowed in included files (only %s)", k.Value, strings.Join(fragmentKeys, ", "))})
+		}
+	}
+	for i := range problems {
+		problems[i].File = file
+	}
+	return f, problems, nil
+}
+
+// resolveIncludes loads the files c includes and merges them in. c was read from path;
+// root is its top-level mapping node, used to locate conflicts (it may be nil).
+// It returns the problems found in the included files, and the conflicts between them.
+func (c *Config) resolveIncludes(path string, root *yaml.Node) (problems, conflicts []Problem, err error) {
+	c.Files = []string{path}
+	c.Sources = map[string]Source{}
+
+	files, err := IncludedFiles(path, c.Include)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fragments []*fragment
+	for _, file := range files {
+		f, p, err := loadFragment(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		problems = append(problems, p...)
+		fragments = append(fragments, f)
+		c.Files = append(c.Files, file)
+	}
+	if root == nil {
+		root = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	conflicts = c.merge(&fragment{file: path, cfg: *c, root: root}, fragments)
+	return problems, conflicts, nil
+}
+
+// origin is where a merged entry came from.
+type origin struct {
+	file string
+	node *yaml.Node
+}
+
+func (o origin) problem(format string, args ...any) Problem {
+	p := Problem{File: o.file, Message: fmt.Sprintf(format, args...)}
+	if o.node != nil {
+		p.Line, p.Column = o.node.Line, o.node.Column
+	}
+	return p
+}
+
+// merge combines the fragments, in order, with the main file. Between fragments the
+// first definition of a name wins and later ones are reported as conflicts; entries
+// of the main file replace fragment entries of the same name.
+func (c *Config) merge(main *fragment, fragments []*fragment) []Problem {
+	var conflicts []Problem
+	var envs []Environment
+	envOrigin := map[string]origin{}
+	index := map[string]int{}
+	dashboards, dashOrigin := map[string]Dashboard{}, map[string]origin{}
+	queries, queryOrigin := map[string]Query{}, map[string]origin{}
+	var defaults Defaults
+
+	for _, f := range fragments {
+		for i, env := range f.cfg.Environments {
+			o := origin{f.file, entryNode(f.root, "environments", i)}
+			if first, ok := envOrigin[env.Name]; ok {
+				if !envDefinedIn(&main.cfg, env.Name) {
+					conflicts = append(conflicts, o.problem("environment %q is also defined in %s", env.Name, first.file))
+				}
+				continue
+			}
+			index[env.Name] = len(envs)
+			envs = append(envs, env)
+			envOrigin[env.Name] = o
+			c.Sources[env.Name] = Source{File: f.file}
+		}
+		for name, d := range f.cfg.Dashboards {
+			o := origin{f.file, mappingValue(mappingValue(f.root, "dashboards"), name)}
+			if first, ok := dashOrigin[name]; ok {
+				if _, overridden := main.cfg.Dashboards[name]; !overridden && !reflect.DeepEqual(d, dashboards[name]) {
+					conflicts = append(conflicts, o.problem("dashboard %q is defined differently in %s", name, first.file))
+				}
+				continue
+			}
+			dashboards[name], dashOrigin[name] = d, o
+		}
+		for name, q := range f.cfg.Queries {
+			o := origin{f.file, mappingValue(mappingValue(f.root, "queries"), name)}
+			if first, ok := queryOrigin[name]; ok {
+				if _, overridden := main.cfg.Queries[name]; !overridden && q != queries[name] {
+					conflicts = append(conflicts, o.problem("query %q is defined differently in %s", name, first.file))
+				}
+				continue
+			}
+			queries[name], queryOrigin[name] = q, o
+		}
+		fillUnset(&defaults, f.cfg.Defaults)
+	}
+
+	// The main file wins
+	for i, env := range c.Environments {
+		o := origin{main.file, entryNode(main.root, "environments", i)}
+		if j, ok := index[env.Name]; ok {
+			envs[j] = env
+			c.Sources[env.Name] = Source{File: main.file, Overrides: envOrigin[env.Name].file}
+		} else {
+			index[env.Name] = len(envs)
+			envs = append(envs, env)
+			c.Sources[env.Name] = Source{File: main.file}
+		}
+		envOrigin[env.Name] = o
+	}
+	for name, d := range c.Dashboards {
+		dashboards[name] = d
+	}
+	for name, q := range c.Queries {
+		queries[name] = q
+	}
+	merged := c.Defaults
+	fillUnset(&merged, defaults)
+
+	c.Environments = envs
+	c.Dashboards = dashboards
+	c.Queries = queries
+	c.Defaults = merged
+
+	// Aliases and base URLs must stay unique across files too
+	for _, key := range []string{"alias", "base_url"} {
+		seen := map[string]string{}
+		for _, env := range envs {
+			value := env.Alias
+			if key == "base_url" {
+				value = strings.TrimSuffix(env.BaseURL, "/")
+			}
+			if value == "" {
+				continue
+			}
+			if other, ok := seen[value]; ok && envOrigin[other].file != envOrigin[env.Name].file {
+				o := envOrigin[env.Name]
+				if n := mappingValue(o.node, key); n != nil {
+					o.node = n
+				}
+				conflicts = append(conflicts, o.problem("%s %q of %s is also used by %s (%s)",
+					key, value, env.Name, other, envOrigin[other].file))
+				continue
+			}
+			seen[value] = env.Name
+		}
+	}
+	return conflicts
+}
+
+func envDefinedIn(c *Config, name string) bool {
+	for _, env := range c.Environments {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// entryNode returns the i-th item of the list under key, or nil.
+func entryNode(root *yaml.Node, key string, i int) *yaml.Node {
+	list := mappingValue(root, key)
+	if list == nil || list.Kind != yaml.SequenceNode || i >= len(list.Content) {
+		return nil
+	}
+	return list.Content[i]
+}
+
+// fillUnset copies the fields of src into the zero fields of dst.
+func fillUnset(dst *Defaults, src Defaults) {
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src)
+	for i := 0; i < d.NumField(); i++ {
+		if d.Field(i).IsZero() {
+			d.Field(i).Set(s.Field(i))
+		}
+	}
+}
+
+// ValidateConfig validates the config at path and every file it includes, and reports
+// conflicts between them. It returns a *ValidationError listing every problem.
+func ValidateConfig(path string) error {
+	err := ValidateFile(path)
+	var invalid *ValidationError
+	if err != nil && !errors.As(err, &invalid) {
+		return err
+	}
+	if invalid == nil {
+		invalid = &ValidationError{File: path}
+	}
+
+	if len(invalid.Problems) == 0 {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		var cfg Config
+		var root *yaml.Node
+		if len(doc.Content) > 0 {
+			root = doc.Content[0]
+			if err := root.Decode(&cfg); err != nil {
+				return err
+			}
+		}
+		problems, conflicts, err := cfg.resolveIncludes(path, root)
+		if err != nil {
+			return err
+		}
+		invalid.Problems = append(invalid.Problems, problems...)
+		invalid.Problems = append(invalid.Problems, conflicts...)
+	}
+
+	if len(invalid.Problems) == 0 {
+		return nil
+	}
+	return invalid
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Query datasources
+const (
+	QueryPrometheus = "prometheus"
+	QueryLoki       = "loki"
+)
+
+// Query is a named Explore query. In YAML it can be written as just the expression.
+type Query struct {
+	// Expr is a PromQL/LogQL Go template ({{ .Namespace }}, {{ .Pod }}, ...)
+	Expr string `mapstructure:"expr" yaml:"expr"`
+	// Datasource is "prometheus" (default) or "loki"
+	Datasource string `mapstructure:"datasource" yaml:"datasource,omitempty"`
+}
+
+// DatasourceType returns the query's datasource, defaulting to Prometheus.
+func (q Query) DatasourceType() string {
+	if q.Datasource == "" {
+		return QueryPrometheus
+	}
+	return q.Datasource
+}
+
+func (q *Query) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		q.Expr = value.Value
+		return nil
+	}
+	type plain Query
+	return value.Decode((*plain)(q))
+}
+
+func (q Query) MarshalYAML() (any, error) {
+	if q.Datasource == "" {
+		return q.Expr, nil
+	}
+	type plain Query
+	return plain(q), nil
+}
+
+// QueriesFor merges the global queries with the env's own (env entries win).
+func (c *Config) QueriesFor(env *Environment) map[string]Query {
+	out := make(map[string]Query, len(c.Queries)+len(env.Queries))
+	for name, q := range c.Queries {
+		out[name] = q
+	}
+	for name, q := range env.Queries {
+		out[name] = q
+	}
+	return out
+}
+
+// QueryNames returns the names from QueriesFor, sorted.
+func (c *Config) QueryNames(env *Environment) []string {
+	all := c.QueriesFor(env)
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveQuery looks up a named query for env.
+func (c *Config) ResolveQuery(env *Environment, name string) (Query, error) {
+	q, ok := c.QueriesFor(env)[name]
+	if !ok {
+		return Query{}, fmt.Errorf("no query named %q for %s (available: %s)",
+			name, env.Name, strings.Join(c.QueryNames(env), ", "))
+	}
+	return q, nil
+}
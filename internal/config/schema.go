@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+)
+
+var clipboardBackends = []string{"auto", "osc52", "wl-copy", "xclip", "xsel", "system", "none"}
+
+// enums lists the allowed values of the fields that take a fixed set.
+var enums = map[reflect.Type]map[string][]string{
+	reflect.TypeOf(Config{}):      {"clipboard": clipboardBackends},
+	reflect.TypeOf(Defaults{}):    {"clipboard": clipboardBackends},
+	reflect.TypeOf(Environment{}): {"explore_format": {"panes", "left"}},
+	reflect.TypeOf(Match{}):       {"mode": {MatchModeAll, MatchModeAny}},
+	reflect.TypeOf(Query{}):       {"datasource": {QueryPrometheus, QueryLoki}},
+}
+
+// required lists the keys a mapping must have.
+var required = map[reflect.Type][]string{
+	reflect.TypeOf(Environment{}): {"name", "base_url"},
+}
+
+// Schema returns a JSON Schema (draft 2020-12) of config.yaml, generated from the
+// same struct tags the loader uses.
+func Schema() map[string]any {
+	s := schemaFor(reflect.TypeOf(Config{}))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = "grafana-connect config"
+	return s
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var s map[string]any
+	switch t.Kind() {
+	case reflect.Struct:
+		keys, fields := yamlFields(t)
+		props := make(map[string]any, len(keys))
+		for _, key := range keys {
+			p := schemaFor(fields[key].Type)
+			if values, ok := enums[t][key]; ok {
+				p["enum"] = values
+			}
+			props[key] = p
+		}
+		if _, ok := props["base_url"]; ok {
+			props["base_url"].(map[string]any)["format"] = "uri"
+		}
+		s = map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+		if req, ok := required[t]; ok {
+			s["required"] = req
+		}
+	case reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Slice:
+		s = map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Int:
+		s = map[string]any{"type": "integer"}
+	default:
+		s = map[string]any{"type": "string"}
+	}
+
+	if hasShortForm(t) {
+		// Also written as a plain string
+		return map[string]any{"anyOf": []any{map[string]any{"type": "string"}, s}}
+	}
+	return s
+}
@@ -0,0 +1,411 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one thing wrong with a config file, at a 1-based line and column.
+type Problem struct {
+	File    string // empty when validating raw data
+	Line    int
+	Column  int
+	Message string
+	// Warning marks problems that loading can ignore, such as unknown keys. `config
+	// validate` still fails on them.
+	Warning bool
+}
+
+func (p Problem) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+}
+
+// ValidationError lists every problem found in a config file and its includes.
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("%s has %d problem(s):", e.File, len(e.Problems)))
+	for _, p := range e.Problems {
+		lines = append(lines, "  "+p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateFile checks the config at path: unknown keys, wrong value types, missing
+// required fields, invalid URLs and regexes, and duplicate names, aliases and base URLs.
+// It returns a *ValidationError listing every problem, or the read/parse error.
+func ValidateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	problems, err := Validate(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(problems) > 0 {
+		for i := range problems {
+			problems[i].File = path
+		}
+		return &ValidationError{File: path, Problems: problems}
+	}
+	return nil
+}
+
+// Validate checks raw YAML and returns its problems sorted by position.
+// The error is only set when the YAML itself can't be parsed.
+func Validate(data []byte) ([]Problem, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil // empty file
+	}
+
+	v := &validator{}
+	root := doc.Content[0]
+	v.checkShape(root, reflect.TypeOf(Config{}))
+	if errs, _ := SplitWarnings(v.problems); len(errs) == 0 {
+		// Only look at the values once the shape is right, so Decode can't fail halfway
+		var cfg Config
+		if err := root.Decode(&cfg); err != nil {
+			v.add(root, "%v", err)
+		} else {
+			v.checkEnvironments(&cfg, mappingValue(root, "environments"))
+		}
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.problems, nil
+}
+
+// SplitWarnings separates the problems that make a config unusable from warnings.
+func SplitWarnings(problems []Problem) (errs, warnings []Problem) {
+	for _, p := range problems {
+		if p.Warning {
+			warnings = append(warnings, p)
+		} else {
+			errs = append(errs, p)
+		}
+	}
+	return errs, warnings
+}
+
+type validator struct {
+	problems []Problem
+	seen     map[Problem]bool // an anchor merged in twice is only reported once
+}
+
+func (v *validator) add(n *yaml.Node, format string, args ...any) {
+	v.report(Problem{Line: n.Line, Column: n.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warn(n *yaml.Node, format string, args ...any) {
+	v.report(Problem{Line: n.Line, Column: n.Column, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+func (v *validator) report(p Problem) {
+	if v.seen == nil {
+		v.seen = map[Problem]bool{}
+	}
+	if !v.seen[p] {
+		v.seen[p] = true
+		v.problems = append(v.problems, p)
+	}
+}
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// hasShortForm reports whether t also accepts a plain string (Dashboard, Query, VarValues).
+func hasShortForm(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+// yamlFields maps the YAML keys of a struct to their fields, in declaration order.
+func yamlFields(t reflect.Type) ([]string, map[string]reflect.StructField) {
+	var keys []string
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		keys = append(keys, key)
+		fields[key] = f
+	}
+	return keys, fields
+}
+
+// checkShape walks the node tree alongside the Go type, reporting unknown keys and
+// values of the wrong kind.
+func (v *validator) checkShape(n *yaml.Node, t reflect.Type) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if n.Kind == yaml.ScalarNode && hasShortForm(t) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			v.add(n, "expected a mapping, got %s", kindName(n))
+			return
+		}
+		keys, fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, val := n.Content[i], n.Content[i+1]
+			if isMergeKey(k) {
+				v.checkMerge(val, t)
+				continue
+			}
+			f, ok := fields[k.Value]
+			if !ok {
+				if hint := closest(k.Value, keys); hint != "" {
+					v.warn(k, "unknown key %q (did you mean %q?)", k.Value, hint)
+				} else {
+					v.warn(k, "unknown key %q", k.Value)
+				}
+				continue
+			}
+			v.checkShape(val, f.Type)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			v.add(n, "expected a mapping, got %s", kindName(n))
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if isMergeKey(n.Content[i]) {
+				v.checkMerge(n.Content[i+1], t)
+				continue
+			}
+			v.checkShape(n.Content[i+1], t.Elem())
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			v.add(n, "expected a list, got %s", kindName(n))
+			return
+		}
+		for _, item := range n.Content {
+			v.checkShape(item, t.Elem())
+		}
+	case reflect.Int:
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!int" {
+			v.add(n, "expected an integer, got %s", kindName(n))
+		}
+	default:
+		if n.Kind != yaml.ScalarNode {
+			v.add(n, "expected a string, got %s", kindName(n))
+		}
+	}
+}
+
+// isMergeKey reports whether k is the YAML merge key of "<<: *base".
+func isMergeKey(k *yaml.Node) bool {
+	return k.Kind == yaml.ScalarNode && k.Tag == "!!merge"
+}
+
+// checkMerge checks the mappings a merge key pulls in (one alias or a list of them) as
+// part of the mapping of type t they're merged into.
+func (v *validator) checkMerge(n *yaml.Node, t reflect.Type) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.SequenceNode {
+		for _, item := range n.Content {
+			v.checkMerge(item, t)
+		}
+		return
+	}
+	if n.Kind != yaml.MappingNode {
+		v.add(n, "<< expects a mapping or a list of mappings, got %s", kindName(n))
+		return
+	}
+	v.checkShape(n, t)
+}
+
+func kindName(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", n.Value)
+	}
+}
+
+// checkEnvironments checks the values of each environment and looks for duplicates.
+// list is the environments sequence node, in the same order as cfg.Environments. When
+// its items can't be lined up with them, problems are reported at the list itself.
+func (v *validator) checkEnvironments(cfg *Config, list *yaml.Node) {
+	if list == nil {
+		return
+	}
+	if list.Kind == yaml.AliasNode {
+		list = list.Alias
+	}
+	located := list.Kind == yaml.SequenceNode && len(list.Content) == len(cfg.Environments)
+	seen := map[string]map[string]int{"name": {}, "alias": {}, "base_url": {}}
+
+	for i := range cfg.Environments {
+		env := &cfg.Environments[i]
+		n := list
+		if located {
+			n = list.Content[i]
+			if n.Kind == yaml.AliasNode {
+				n = n.Alias
+			}
+		}
+		at := func(key string) *yaml.Node {
+			if val := mappingValue(n, key); val != nil {
+				return val
+			}
+			return n
+		}
+		dup := func(key, value string) {
+			if value == "" {
+				return
+			}
+			if first, ok := seen[key][value]; ok {
+				report := v.add
+				if key == "base_url" {
+					report = v.warn // several clusters behind one Grafana is fine, but worth a look
+				}
+				report(at(key), "duplicate %s %q (also used by environments[%d])", key, value, first)
+				return
+			}
+			seen[key][value] = i
+		}
+
+		if env.Name == "" {
+			v.add(at("name"), "environments[%d]: name is required", i)
+		}
+		dup("name", env.Name)
+		dup("alias", env.Alias)
+
+		if env.BaseURL == "" {
+			v.add(at("base_url"), "environments[%d]: base_url is required", i)
+		} else if err := checkURL(env.BaseURL); err != nil {
+			v.add(at("base_url"), "base_url: %v", err)
+		}
+		dup("base_url", strings.TrimSuffix(env.BaseURL, "/"))
+
+		v.checkRegex(at("context_match"), "context_match", env.ContextMatch)
+		if m := env.Match; m != nil {
+			mn := mappingValue(n, "match")
+			if mn == nil {
+				mn = n
+			}
+			matchAt := func(key string) *yaml.Node {
+				if val := mappingValue(mn, key); val != nil {
+					return val
+				}
+				return mn
+			}
+			if m.Mode != "" && m.Mode != MatchModeAll && m.Mode != MatchModeAny {
+				v.add(matchAt("mode"), "match.mode: %q is not %q or %q", m.Mode, MatchModeAll, MatchModeAny)
+			}
+			v.checkRegex(matchAt("server"), "match.server", m.Server)
+			v.checkRegex(matchAt("cluster"), "match.cluster", m.Cluster)
+			v.checkRegex(matchAt("user"), "match.user", m.User)
+			v.checkRegex(matchAt("namespace"), "match.namespace", m.Namespace)
+		}
+	}
+}
+
+func (v *validator) checkRegex(n *yaml.Node, field, pattern string) {
+	if pattern == "" {
+		return
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		v.add(n, "%s: %v", field, err)
+	}
+}
+
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must start with http:// or https://", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", raw)
+	}
+	return nil
+}
+
+// mappingValue returns the value node of key in a mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// closest returns the known key within two edits of key, if any, to suggest for typos.
+func closest(key string, known []string) string {
+	best, bestDist := "", 3
+	for _, k := range known {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEnvironmentsAlias(t *testing.T) {
+	data := []byte(`
+shared: &envs
+  - name: prod
+    base_url: https://grafana.example.com
+    context_match: "prod-("
+  - name: prod
+    base_url: https://other.example.com
+environments: *envs
+`)
+	problems, err := Validate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := SplitWarnings(problems)
+
+	var messages []string
+	for _, p := range errs {
+		messages = append(messages, p.Message)
+	}
+	got := strings.Join(messages, "\n")
+	for _, want := range []string{"context_match", `duplicate name "prod"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing a problem about %s in:\n%s", want, got)
+		}
+	}
+	// Located on the aliased items, not on the alias
+	for _, p := range errs {
+		if p.Line < 3 || p.Line > 7 {
+			t.Errorf("problem %q at line %d, want it within the anchored list", p.Message, p.Line)
+		}
+	}
+}
+
+func TestValidateDuplicateBaseURL(t *testing.T) {
+	data := []byte(`
+environments:
+  - name: dev
+    base_url: https://grafana.example.com
+  - name: staging
+    base_url: https://grafana.example.com/
+`)
+	problems, err := Validate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, warnings := SplitWarnings(problems)
+	if len(errs) != 0 {
+		t.Errorf("got errors %v, want none", errs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "duplicate base_url") || warnings[0].Line != 6 {
+		t.Errorf("got warnings %v, want one duplicate base_url at line 6", warnings)
+	}
+
+	// Still loadable
+	if err := checkLoadable("config.yaml", problems); err != nil {
+		t.Errorf("checkLoadable: %v", err)
+	}
+}
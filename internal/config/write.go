@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// Bytes renders the document. yaml.v3 keeps comments, key order, quoting and anchors;
+// the indentation and blank lines of the original file are put back on top of that.
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.doc.Content) == 0 {
+		return nil, nil
+	}
+	untagMergeKeys(d.doc)
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(indentOf(d.src))
+	if err := enc.Encode(d.doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	if len(d.src) == 0 {
+		return b.Bytes(), nil
+	}
+	return restoreBlankLines(b.Bytes(), d.doc, d.src), nil
+}
+
+// Save writes the document atomically: to a temp file that is renamed over the
+// config, keeping the previous version as <path>.bak. A symlinked config (dotfiles)
+// is written through the link.
+func (d *Document) Save() error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+
+	path := d.Path
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if old, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+".bak", old, 0600); err != nil {
+			return err
+		}
+	}
+	return atomicfile.WriteFile(path, data, 0600)
+}
+
+// untagMergeKeys clears the tag of merge keys, which yaml.v3 would otherwise write
+// out as "!!merge <<". Decoding still treats a plain "<<" as a merge.
+func untagMergeKeys(n *yaml.Node) {
+	if isMergeKey(n) {
+		n.Tag = ""
+	}
+	for _, c := range n.Content {
+		untagMergeKeys(c)
+	}
+}
+
+// indentOf guesses the indentation step of a YAML file from its least indented
+// nested line. Defaults to 2.
+func indentOf(src []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(src), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		n := len(line) - len(trimmed)
+		if n == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent == 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 2 {
+		return 2
+	}
+	return indent
+}
+
+// restoreBlankLines puts back the blank lines the source had before keys and list
+// items, which yaml.v3 drops. out is the encoding of doc, which was parsed from src
+// and possibly edited since; nodes added by edits have no source line and get none.
+func restoreBlankLines(out []byte, doc *yaml.Node, src []byte) []byte {
+	var encoded yaml.Node
+	if err := yaml.Unmarshal(out, &encoded); err != nil {
+		return out
+	}
+	srcLines := strings.Split(string(src), "\n")
+	blankBefore := map[int]bool{}
+
+	// onlyBlanks reports whether the source lines strictly between from and to are blank.
+	onlyBlanks := func(from, to int) bool {
+		for l := from + 1; l < to && l-1 < len(srcLines); l++ {
+			if strings.TrimSpace(srcLines[l-1]) != "" {
+				return false
+			}
+		}
+		return true
+	}
+	// mark keeps the blank line before orig. The first entry of a collection only keeps
+	// it if it was already first, i.e. nothing but blank lines separate it from its
+	// parent's line; otherwise the blank belonged between it and a removed entry. The
+	// first key of a list item starts on the item's line, which has been marked already.
+	mark := func(orig, enc *yaml.Node, first bool, parentLine int) {
+		line := firstLine(orig)
+		if orig.Line == 0 || line < 2 || line-2 >= len(srcLines) {
+			return
+		}
+		if first && parentLine > 0 && (line <= parentLine || !onlyBlanks(parentLine, line)) {
+			return
+		}
+		if strings.TrimSpace(srcLines[line-2]) == "" {
+			blankBefore[firstLine(enc)] = true
+		}
+	}
+	var walk func(orig, enc *yaml.Node, parentLine int)
+	walk = func(orig, enc *yaml.Node, parentLine int) {
+		if orig.Kind != enc.Kind || len(orig.Content) != len(enc.Content) {
+			return
+		}
+		switch orig.Kind {
+		case yaml.DocumentNode:
+			for i := range orig.Content {
+				walk(orig.Content[i], enc.Content[i], 0)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(orig.Content); i += 2 {
+				mark(orig.Content[i], enc.Content[i], i == 0, parentLine)
+				walk(orig.Content[i+1], enc.Content[i+1], orig.Content[i].Line)
+			}
+		case yaml.SequenceNode:
+			for i := range orig.Content {
+				mark(orig.Content[i], enc.Content[i], i == 0, parentLine)
+				walk(orig.Content[i], enc.Content[i], orig.Content[i].Line)
+			}
+		}
+	}
+	walk(doc, &encoded, 0)
+
+	lines := strings.Split(string(out), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		if blankBefore[i+1] && i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(line)
+		if i < len(lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// firstLine is the line a node starts on, including its head comment.
+func firstLine(n *yaml.Node) int {
+	if n.HeadComment == "" {
+		return n.Line
+	}
+	return n.Line - strings.Count(n.HeadComment, "\n") - 1
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// saveFixture has what a save must not lose: comments, an anchor with merge keys,
+// a key this version doesn't know and quoting.
+const saveFixture = `# Managed by hand, edit with care
+base: &base
+  username: grafana-ro # shared account
+  org_id: 2
+
+some_future_key: {enabled: true}
+
+environments:
+  - <<: *base
+    name: dev
+    base_url: "https://dev.example.com"
+
+  # production
+  - <<: *base
+    name: prod
+    base_url: "https://prod.example.com"
+`
+
+func TestSave(t *testing.T) {
+	d := openFixture(t, saveFixture)
+	if err := d.Set("environments.prod.priority", "10"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(d.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(saveFixture, `    base_url: "https://prod.example.com"
+`, `    base_url: "https://prod.example.com"
+    priority: 10
+`, 1)
+	if string(got) != want {
+		t.Errorf("saved:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The anchor still feeds both environments
+	saved, err := OpenDocument(d.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := saved.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Environments) != 2 || cfg.Environments[1].Username != "grafana-ro" || cfg.Environments[1].OrgID != 2 {
+		t.Errorf("decoded %+v", cfg.Environments)
+	}
+
+	bak, err := os.ReadFile(d.Path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bak) != saveFixture {
+		t.Errorf("backup:\n%s\nwant the previous file", bak)
+	}
+
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("saved with mode %v, want 0600 (it may hold passwords)", perm)
+	}
+	// No temp files left behind
+	entries, _ := os.ReadDir(filepath.Dir(d.Path))
+	if len(entries) != 2 {
+		t.Errorf("got %d files next to the config, want it and its .bak", len(entries))
+	}
+}
+
+func TestSaveThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "grafana-connect.yaml")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte(saveFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	d, err := OpenDocument(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("clipboard", "none"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("the link was replaced by a file (%v)", err)
+	}
+	got, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(got), "clipboard: none\n") {
+		t.Errorf("target not updated:\n%s", got)
+	}
+}
+
+func TestSaveNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grafana-connect", "config.yaml")
+	d, err := OpenDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := d.AddEnv("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetEnv(i, "base_url", "https://dev.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "environments:\n  - name: dev\n    base_url: https://dev.example.com\n"; string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("a new file has nothing to back up, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/secret"
+)
+
+// Client talks to the Grafana HTTP API of a single instance.
+// A Token (service account) takes precedence over basic auth.
+type Client struct {
+	BaseURL    string
+	Username   string
+	Password   string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("grafana API returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("grafana API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// NewClient returns a client for baseURL with a sensible default timeout.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ForEnv builds a client from the environment, resolving password_ref and token_ref.
+func ForEnv(env config.Environment) (*Client, error) {
+	c := NewClient(env.BaseURL)
+	c.Username = env.Username
+
+	token, err := secret.Token(env)
+	if err != nil {
+		return nil, fmt.Errorf("token for %s: %w", env.Name, err)
+	}
+	c.Token = token
+	if c.Token != "" {
+		return c, nil
+	}
+
+	c.Password, err = secret.Password(env)
+	if err != nil {
+		return nil, fmt.Errorf("password for %s: %w", env.Name, err)
+	}
+	return c, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	return req, nil
+}
+
+// do sends the request and decodes a JSON response into out (if non-nil).
+func (c *Client) do(req *http.Request, out any) error {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		// Grafana errors look like {"message": "..."}
+		var payload struct {
+			Message string `json:"message"`
+		}
+		if data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096)); json.Unmarshal(data, &payload) == nil {
+			apiErr.Message = payload.Message
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s response: %w", req.URL.Path, err)
+	}
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, path, nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	return c.do(req, out)
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, out)
+}
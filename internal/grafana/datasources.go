@@ -0,0 +1,48 @@
+package grafana
+
+import (
+	"context"
+	"sort"
+)
+
+// Datasource is the subset of /api/datasources we care about.
+type Datasource struct {
+	ID        int    `json:"id"`
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// Datasources lists every datasource visible to the client's user.
+func (c *Client) Datasources(ctx context.Context) ([]Datasource, error) {
+	var ds []Datasource
+	if err := c.get(ctx, "/api/datasources", nil, &ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// DatasourcesOfType returns datasources of the given plugin type (e.g. "prometheus"),
+// default datasource first, then by name.
+func (c *Client) DatasourcesOfType(ctx context.Context, typ string) ([]Datasource, error) {
+	all, err := c.Datasources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Datasource
+	for _, d := range all {
+		if d.Type == typ {
+			out = append(out, d)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].IsDefault != out[j].IsDefault {
+			return out[i].IsDefault
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
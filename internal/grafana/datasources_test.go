@@ -0,0 +1,85 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeDatasources stands in for /api/datasources, accepting only the given credentials.
+func fakeDatasources(t *testing.T, user, pass string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/datasources" {
+			http.NotFound(w, r)
+			return
+		}
+		if u, p, ok := r.BasicAuth(); !ok || u != user || p != pass {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"invalid username or password"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"id":1,"uid":"loki","name":"Loki","type":"loki"},
+			{"id":2,"uid":"thanos","name":"Thanos","type":"prometheus"},
+			{"id":3,"uid":"amp","name":"AMP","type":"prometheus"},
+			{"id":4,"uid":"prom","name":"Prometheus","type":"prometheus","isDefault":true}
+		]`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDatasourcesOfType(t *testing.T) {
+	srv := fakeDatasources(t, "admin", "secret")
+	c := NewClient(srv.URL + "/")
+	c.Username, c.Password = "admin", "secret"
+
+	got, err := c.DatasourcesOfType(context.Background(), "prometheus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Default first, then by name
+	want := []string{"prom", "amp", "thanos"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d datasources, want %d: %+v", len(got), len(want), got)
+	}
+	for i, uid := range want {
+		if got[i].UID != uid {
+			t.Errorf("datasource %d: got %q, want %q", i, got[i].UID, uid)
+		}
+	}
+}
+
+func TestDatasourcesUnauthorized(t *testing.T) {
+	srv := fakeDatasources(t, "admin", "secret")
+	c := NewClient(srv.URL)
+	c.Username, c.Password = "admin", "wrong"
+
+	_, err := c.Datasources(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "invalid username or password" {
+		t.Errorf("got %d %q", apiErr.StatusCode, apiErr.Message)
+	}
+}
+
+func TestTokenTakesPrecedence(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer glsa_test" {
+			t.Errorf("Authorization: got %q", got)
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.Username, c.Password, c.Token = "admin", "secret", "glsa_test"
+	if _, err := c.Datasources(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,45 @@
+package grafana
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// Health returns the Grafana version reported by /api/health (no auth needed).
+func (c *Client) Health(ctx context.Context) (string, error) {
+	var resp struct {
+		Database string `json:"database"`
+		Version  string `json:"version"`
+	}
+	if err := c.get(ctx, "/api/health", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+// CurrentOrg returns the name of the org the credentials belong to. It works for
+// both users and service-account tokens, so it doubles as a login check.
+func (c *Client) CurrentOrg(ctx context.Context) (string, error) {
+	var resp struct {
+		Name string `json:"name"`
+	}
+	if err := c.get(ctx, "/api/org", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Name, nil
+}
+
+// DashboardTitle looks up a dashboard by its "uid/slug" path and returns its title.
+func (c *Client) DashboardTitle(ctx context.Context, path string) (string, error) {
+	uid, _, _ := strings.Cut(path, "/")
+	var resp struct {
+		Dashboard struct {
+			Title string `json:"title"`
+		} `json:"dashboard"`
+	}
+	if err := c.get(ctx, "/api/dashboards/uid/"+url.PathEscape(uid), nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Dashboard.Title, nil
+}
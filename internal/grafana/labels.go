@@ -0,0 +1,28 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// LabelValues returns the values of a label in a Prometheus datasource, sorted. The
+// query goes through Grafana's datasource proxy, so Grafana credentials are enough.
+func (c *Client) LabelValues(ctx context.Context, datasourceUID, label string) ([]string, error) {
+	var resp struct {
+		Status string   `json:"status"`
+		Data   []string `json:"data"`
+		Error  string   `json:"error"`
+	}
+	path := "/api/datasources/uid/" + url.PathEscape(datasourceUID) +
+		"/resources/api/v1/label/" + url.PathEscape(label) + "/values"
+	if err := c.get(ctx, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("label values of %s: %s", label, resp.Error)
+	}
+	sort.Strings(resp.Data)
+	return resp.Data, nil
+}
@@ -0,0 +1,59 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeLabelValues stands in for the datasource proxy of Prometheus datasource "prom",
+// answering label value queries with body.
+func fakeLabelValues(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/datasources/uid/prom/resources/api/v1/label/namespace/values" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLabelValues(t *testing.T) {
+	srv := fakeLabelValues(t, `{"status":"success","data":["payments","kube-system","default"]}`)
+	c := NewClient(srv.URL)
+
+	got, err := c.LabelValues(context.Background(), "prom", "namespace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "default,kube-system,payments" {
+		t.Errorf("got %v, want them sorted", got)
+	}
+}
+
+func TestLabelValuesError(t *testing.T) {
+	srv := fakeLabelValues(t, `{"status":"error","error":"query timed out"}`)
+	c := NewClient(srv.URL)
+
+	_, err := c.LabelValues(context.Background(), "prom", "namespace")
+	if err == nil || !strings.Contains(err.Error(), "query timed out") {
+		t.Errorf("got %v, want the Prometheus error", err)
+	}
+}
+
+func TestLabelValuesUnknownDatasource(t *testing.T) {
+	srv := fakeLabelValues(t, `{}`)
+	c := NewClient(srv.URL)
+
+	_, err := c.LabelValues(context.Background(), "other", "namespace")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("got %v, want a 404 *APIError", err)
+	}
+}
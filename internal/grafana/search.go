@@ -0,0 +1,66 @@
+package grafana
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"sort"
+)
+
+// Search result types
+const (
+	TypeDashboard = "dash-db"
+	TypeFolder    = "dash-folder"
+)
+
+// SearchHit is one entry of /api/search.
+type SearchHit struct {
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"` // e.g. "/d/abc123/pod-resources", may include a sub-path
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	FolderUID   string   `json:"folderUid"`
+	FolderTitle string   `json:"folderTitle"`
+}
+
+// Path returns the "uid/slug" form used by the dashboard field of the config.
+func (h SearchHit) Path() string {
+	slug := path.Base(h.URL)
+	if slug == "" || slug == "." || slug == "/" || slug == h.UID {
+		return h.UID
+	}
+	return h.UID + "/" + slug
+}
+
+// Folder returns the folder title, with dashboards at the root shown under "General".
+func (h SearchHit) Folder() string {
+	if h.FolderTitle == "" {
+		return "General"
+	}
+	return h.FolderTitle
+}
+
+// SearchDashboards returns dashboards whose title matches query (all when empty),
+// sorted by folder then title.
+func (c *Client) SearchDashboards(ctx context.Context, query string) ([]SearchHit, error) {
+	q := url.Values{}
+	q.Set("type", TypeDashboard)
+	q.Set("limit", "5000")
+	if query != "" {
+		q.Set("query", query)
+	}
+
+	var hits []SearchHit
+	if err := c.get(ctx, "/api/search", q, &hits); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Folder() != hits[j].Folder() {
+			return hits[i].Folder() < hits[j].Folder()
+		}
+		return hits[i].Title < hits[j].Title
+	})
+	return hits, nil
+}
@@ -0,0 +1,28 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ShortURL turns a full link on this instance into a /goto/<uid> short link.
+func (c *Client) ShortURL(ctx context.Context, longURL string) (string, error) {
+	// The API wants the path relative to the Grafana root, e.g. "d/abc/pods?orgId=1"
+	path, ok := strings.CutPrefix(longURL, c.BaseURL+"/")
+	if !ok {
+		return "", fmt.Errorf("%s is not on %s", longURL, c.BaseURL)
+	}
+
+	var resp struct {
+		UID string `json:"uid"`
+		URL string `json:"url"`
+	}
+	if err := c.post(ctx, "/api/short-urls", map[string]string{"path": path}, &resp); err != nil {
+		return "", err
+	}
+	if resp.URL == "" {
+		return "", fmt.Errorf("grafana returned no short URL")
+	}
+	return resp.URL, nil
+}
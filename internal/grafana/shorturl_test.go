@@ -0,0 +1,61 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestShortURL(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/grafana/api/short-urls" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Path string `json:"path"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		gotPath = body.Path
+		_, _ = w.Write([]byte(`{"uid":"abc123","url":"http://` + r.Host + `/grafana/goto/abc123?orgId=1"}`))
+	}))
+	defer srv.Close()
+
+	// Grafana served under a sub-path
+	c := NewClient(srv.URL + "/grafana")
+	short, err := c.ShortURL(context.Background(), srv.URL+"/grafana/d/pods/pod-resources?orgId=1&var-namespace=payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "d/pods/pod-resources?orgId=1&var-namespace=payments"; gotPath != want {
+		t.Errorf("sent path %q, want %q", gotPath, want)
+	}
+	if !strings.HasSuffix(short, "/grafana/goto/abc123?orgId=1") {
+		t.Errorf("got short URL %q", short)
+	}
+}
+
+func TestShortURLOtherInstance(t *testing.T) {
+	c := NewClient("https://grafana.example.com")
+	if _, err := c.ShortURL(context.Background(), "https://other.example.com/d/pods"); err == nil {
+		t.Fatal("expected an error for a URL on another instance")
+	}
+}
+
+func TestShortURLEmptyResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	if _, err := c.ShortURL(context.Background(), srv.URL+"/d/pods"); err == nil {
+		t.Fatal("expected an error when Grafana returns no URL")
+	}
+}
@@ -0,0 +1,159 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/atomicfile"
+)
+
+// maxEntries is how many launches the file keeps.
+const maxEntries = 1000
+
+// Entry is one launch.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Env       string    `json:"env"`
+	Namespace string    `json:"namespace,omitempty"`
+	Context   string    `json:"context,omitempty"`
+	Cluster   string    `json:"cluster,omitempty"`   // kubeconfig cluster, e.g. from --cluster
+	Dashboard string    `json:"dashboard,omitempty"` // "uid/slug"
+	Workload  string    `json:"workload,omitempty"`  // "kind/name", as --workload takes it
+	Pod       string    `json:"pod,omitempty"`
+	// Query and Datasource are set for Explore launches instead of Dashboard
+	Query      string `json:"query,omitempty"`
+	Datasource string `json:"datasource,omitempty"`
+
+	// Uses is how often the target was opened, filled in by Distinct.
+	Uses int `json:"-"`
+}
+
+// Key identifies what was opened, whenever it was.
+func (e Entry) Key() string {
+	return e.Env + "\x00" + e.Namespace + "\x00" + e.Dashboard + "\x00" + e.Workload + "\x00" +
+		e.Pod + "\x00" + e.Query + "\x00" + e.Datasource
+}
+
+// Path is $XDG_STATE_HOME/grafana-connect/history.jsonl, or under ~/.local/state.
+func Path() string {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "grafana-connect", "history.jsonl")
+}
+
+// Load returns every recorded launch, oldest first. Unreadable lines are skipped.
+func Load() ([]Entry, error) {
+	data, err := os.ReadFile(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.Env != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Record appends a launch, dropping the oldest ones past maxEntries.
+func Record(e Entry) error {
+	entries, err := Load()
+	if err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	entries = append(entries, e)
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	// Replaced atomically, so concurrent launches never see half a file
+	if err := os.MkdirAll(filepath.Dir(Path()), 0700); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(Path(), buf.Bytes(), 0600)
+}
+
+// Distinct returns the latest entry of every target, most recent first, with Uses set.
+func Distinct(entries []Entry) []Entry {
+	index := map[string]int{}
+	var out []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if j, ok := index[e.Key()]; ok {
+			out[j].Uses++
+			continue
+		}
+		e.Uses = 1
+		index[e.Key()] = len(out)
+		out = append(out, e)
+	}
+	return out
+}
+
+// Previous is the target opened before the last one, like `cd -`.
+func Previous(entries []Entry) (Entry, bool) {
+	distinct := Distinct(entries)
+	if len(distinct) < 2 {
+		return Entry{}, false
+	}
+	return distinct[1], true
+}
+
+// Frecency scores the keys of entries by how often and how recently they were used:
+// a use counts 4 within the hour, 2 within the day, 1 within the week and 0.25 after.
+func Frecency(entries []Entry, key func(Entry) string) map[string]float64 {
+	scores := map[string]float64{}
+	now := time.Now()
+	for _, e := range entries {
+		k := key(e)
+		if k == "" {
+			continue
+		}
+		switch age := now.Sub(e.Time); {
+		case age < time.Hour:
+			scores[k] += 4
+		case age < 24*time.Hour:
+			scores[k] += 2
+		case age < 7*24*time.Hour:
+			scores[k]++
+		default:
+			scores[k] += 0.25
+		}
+	}
+	return scores
+}
+
+// SortByFrecency orders items by the score of their key, highest first. Items with
+// equal scores (e.g. never used) keep their order.
+func SortByFrecency[T any](items []T, scores map[string]float64, key func(T) string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return scores[key(items[i])] > scores[key(items[j])]
+	})
+}
@@ -0,0 +1,93 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Overrides are kubectl's global flags. Every kubeconfig access in this package goes
+// through them.
+type Overrides struct {
+	Kubeconfig string // --kubeconfig: this file only, instead of $KUBECONFIG / ~/.kube/config
+	Context    string // --context: use this context instead of the current one
+	Cluster    string // --cluster: use this cluster instead of the context's
+}
+
+var overrides = &Overrides{}
+
+// UseOverrides makes every later kubeconfig access honor o. The pointer is kept, so
+// flags bound to its fields apply once they are parsed.
+func UseOverrides(o *Overrides) {
+	overrides = o
+}
+
+// loadingRules finds the kubeconfig like kubectl: --kubeconfig if set, else every file
+// of the colon-separated $KUBECONFIG merged (the first file to set a value wins),
+// else ~/.kube/config.
+func loadingRules() *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = overrides.Kubeconfig
+	return rules
+}
+
+// clientConfig is the shared client-config factory. contextName, when set, wins over --context.
+// --cluster only applies to the context in use, not to other contexts asked for by name.
+func clientConfig(contextName string) clientcmd.ClientConfig {
+	o := &clientcmd.ConfigOverrides{CurrentContext: overrides.Context}
+	if contextName == "" || contextName == selectedContext() {
+		o.Context.Cluster = overrides.Cluster
+	}
+	if contextName != "" {
+		o.CurrentContext = contextName
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules(), o)
+}
+
+// loadRaw loads the merged kubeconfig with --context applied as its current context.
+func loadRaw() (*clientcmdapi.Config, error) {
+	raw, err := loadingRules().Load()
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig: %w", err)
+	}
+	if overrides.Context != "" {
+		if _, ok := raw.Contexts[overrides.Context]; !ok {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", overrides.Context)
+		}
+		raw.CurrentContext = overrides.Context
+	}
+	if overrides.Cluster != "" {
+		if _, ok := raw.Clusters[overrides.Cluster]; !ok {
+			return nil, fmt.Errorf("cluster %q not found in kubeconfig", overrides.Cluster)
+		}
+	}
+	return raw, nil
+}
+
+// selectedContext is --context, else the current context of the kubeconfig.
+func selectedContext() string {
+	if overrides.Context != "" {
+		return overrides.Context
+	}
+	raw, err := loadingRules().Load()
+	if err != nil {
+		return ""
+	}
+	return raw.CurrentContext
+}
+
+// ListClusters returns the cluster names of the kubeconfig, sorted.
+func ListClusters() ([]string, error) {
+	raw, err := loadRaw()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(raw.Clusters))
+	for name := range raw.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,148 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+)
+
+const kubeconfigA = `apiVersion: v1
+kind: Config
+current-context: ctx-a
+clusters:
+  - name: cluster-a
+    cluster: {server: https://a.example.com}
+contexts:
+  - name: ctx-a
+    context: {cluster: cluster-a, user: user-a, namespace: team-a}
+users:
+  - name: user-a
+    user: {token: a}
+`
+
+// kubeconfigB also sets a current context and redefines cluster-a; the first file wins both.
+const kubeconfigB = `apiVersion: v1
+kind: Config
+current-context: ctx-b
+clusters:
+  - name: cluster-a
+    cluster: {server: https://shadowed.example.com}
+  - name: cluster-b
+    cluster: {server: https://b.example.com}
+  - name: cluster-c
+    cluster: {server: https://c.example.com}
+contexts:
+  - name: ctx-b
+    context: {cluster: cluster-b, user: user-b}
+users:
+  - name: user-b
+    user: {token: b}
+`
+
+// useKubeconfigs points $KUBECONFIG at the given files and installs o for the test.
+func useKubeconfigs(t *testing.T, o Overrides, contents ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for i, c := range contents {
+		path := filepath.Join(dir, "config-"+string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte(c), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	t.Setenv("KUBECONFIG", strings.Join(paths, string(os.PathListSeparator)))
+	prev := overrides
+	UseOverrides(&o)
+	t.Cleanup(func() { overrides = prev })
+}
+
+func TestKubeconfigMerge(t *testing.T) {
+	useKubeconfigs(t, Overrides{}, kubeconfigA, kubeconfigB)
+
+	states, err := ListContexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 2 || states[0].Context != "ctx-a" || states[1].Context != "ctx-b" {
+		t.Fatalf("got contexts %+v", states)
+	}
+
+	state, err := GetCurrentState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := KubeState{Context: "ctx-a", Namespace: "team-a", Cluster: "cluster-a", Server: "https://a.example.com", User: "user-a"}
+	if *state != want {
+		t.Errorf("got %+v, want %+v", *state, want)
+	}
+}
+
+func TestContextOverride(t *testing.T) {
+	useKubeconfigs(t, Overrides{Context: "ctx-b"}, kubeconfigA, kubeconfigB)
+
+	state, err := GetCurrentState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Context != "ctx-b" || state.Namespace != "default" || state.Server != "https://b.example.com" {
+		t.Errorf("got %+v", *state)
+	}
+
+	// Environment matching is skipped for --context
+	state, err = FindStateForEnv(&config.Environment{Name: "a", ContextMatch: "ctx-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Context != "ctx-b" {
+		t.Errorf("got context %q, want ctx-b", state.Context)
+	}
+
+	useKubeconfigs(t, Overrides{Context: "nope"}, kubeconfigA)
+	if _, err := GetCurrentState(); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+}
+
+func TestClusterOverride(t *testing.T) {
+	useKubeconfigs(t, Overrides{Cluster: "cluster-c"}, kubeconfigA, kubeconfigB)
+
+	state, err := GetCurrentState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Context != "ctx-a" || state.Cluster != "cluster-c" || state.Server != "https://c.example.com" {
+		t.Errorf("current context: got %+v", *state)
+	}
+
+	// Only the context in use gets the cluster
+	states, err := ListContexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if states[0].Cluster != "cluster-a" {
+		t.Errorf("ListContexts: got cluster %q for ctx-a, want cluster-a", states[0].Cluster)
+	}
+	state, err = FindStateForEnv(&config.Environment{Name: "b", ContextMatch: "ctx-b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Cluster != "cluster-b" || state.Server != "https://b.example.com" {
+		t.Errorf("other context: got %+v", *state)
+	}
+	state, err = FindStateForEnv(&config.Environment{Name: "a", ContextMatch: "ctx-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Cluster != "cluster-c" {
+		t.Errorf("current context: got cluster %q, want cluster-c", state.Cluster)
+	}
+
+	useKubeconfigs(t, Overrides{Cluster: "nope"}, kubeconfigA)
+	if _, err := GetCurrentState(); err == nil {
+		t.Error("expected an error for an unknown cluster")
+	}
+}
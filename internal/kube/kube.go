@@ -3,31 +3,64 @@ package kube
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"sort"
 	"time"
 
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 type KubeState struct {
 	Context   string
 	Namespace string
+	Cluster   string // kubeconfig cluster name
+	Server    string // API server URL of the cluster
+	User      string // kubeconfig auth-info name
 }
 
-func GetCurrentState() (*KubeState, error) {
-	// 1. Load the default kubeconfig (~/.kube/config)
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
+// stateFor describes a single context from a raw kubeconfig. Namespace is the
+// context's own default namespace, falling back to "default". --cluster is left out,
+// see selectedState.
+func stateFor(raw clientcmdapi.Config, ctxName string) *KubeState {
+	state := &KubeState{Context: ctxName, Namespace: "default"}
+	ctx, ok := raw.Contexts[ctxName]
+	if !ok {
+		return state
+	}
+	if ctx.Namespace != "" {
+		state.Namespace = ctx.Namespace
+	}
+	state.Cluster = ctx.Cluster
+	state.User = ctx.AuthInfo
+	if cluster, ok := raw.Clusters[state.Cluster]; ok {
+		state.Server = cluster.Server
+	}
+	return state
+}
+
+// selectedState is stateFor the context in use, the only one --cluster applies to (as in kubectl).
+func selectedState(raw clientcmdapi.Config, ctxName string) *KubeState {
+	state := stateFor(raw, ctxName)
+	if overrides.Cluster != "" {
+		state.Cluster = overrides.Cluster
+		state.Server = ""
+		if cluster, ok := raw.Clusters[state.Cluster]; ok {
+			state.Server = cluster.Server
+		}
+	}
+	return state
+}
 
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+func GetCurrentState() (*KubeState, error) {
+	// 1. Load the kubeconfig (--kubeconfig, $KUBECONFIG or ~/.kube/config)
+	kubeConfig := clientConfig("")
 
-	// 2. Extract RawConfig to get the CurrentContext name
-	rawConfig, err := kubeConfig.RawConfig()
+	// 2. Extract RawConfig to get the CurrentContext name (or --context)
+	rawConfig, err := loadRaw()
 	if err != nil {
-		return nil, fmt.Errorf("could not load kubeconfig: %w", err)
+		return nil, err
 	}
 
 	currentCtxName := rawConfig.CurrentContext
@@ -42,19 +75,15 @@ func GetCurrentState() (*KubeState, error) {
 		ns = "default" // Safe fallback
 	}
 
-	return &KubeState{
-		Context:   currentCtxName,
-		Namespace: ns,
-	}, nil
+	state := selectedState(*rawConfig, currentCtxName)
+	state.Namespace = ns
+	return state, nil
 }
 
-func GetNamespaces(contextName string) ([]string, error) {
+// clientsetFor builds a clientset for a specific kubeconfig context.
+func clientsetFor(contextName string) (*kubernetes.Clientset, error) {
 	// 1. Build Config for the specific context
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
-
-	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
-	restConfig, err := clientConfig.ClientConfig()
+	restConfig, err := clientConfig(contextName).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config for context %s: %w", contextName, err)
 	}
@@ -64,6 +93,14 @@ func GetNamespaces(contextName string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
 	}
+	return clientset, nil
+}
+
+func GetNamespaces(contextName string) ([]string, error) {
+	clientset, err := clientsetFor(contextName)
+	if err != nil {
+		return nil, err
+	}
 
 	// 3. Call K8s API
 	// Set a timeout to avoid hanging if the cluster is unreachable
@@ -85,22 +122,57 @@ func GetNamespaces(contextName string) ([]string, error) {
 	return namespaces, nil
 }
 
-// FindContextByRegex looks through ~/.kube/config and returns the first context matching the regex
-func FindContextByRegex(regexStr string) (string, error) {
-	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+// ListContexts describes every context of the kubeconfig, sorted by name.
+func ListContexts() ([]*KubeState, error) {
+	raw, err := loadRaw()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	states := make([]*KubeState, 0, len(raw.Contexts))
+	for ctxName := range raw.Contexts {
+		states = append(states, stateFor(*raw, ctxName))
 	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Context < states[j].Context })
+	return states, nil
+}
 
-	r, err := regexp.Compile(regexStr)
+// FindContextForEnv looks through the kubeconfig and returns the name of the context that
+// best matches the environment's context_match and match rules.
+func FindContextForEnv(env *config.Environment) (string, error) {
+	state, err := FindStateForEnv(env)
 	if err != nil {
 		return "", err
 	}
+	return state.Context, nil
+}
 
-	for ctxName := range config.Contexts {
-		if r.MatchString(ctxName) {
-			return ctxName, nil
-		}
+// FindStateForEnv is FindContextForEnv returning the whole context description.
+// The most specific match wins, then the current context, then the alphabetically first name.
+// With --context that context is used as is, and the current context picks up --cluster.
+func FindStateForEnv(env *config.Environment) (*KubeState, error) {
+	raw, err := loadRaw()
+	if err != nil {
+		return nil, err
+	}
+	if overrides.Context != "" {
+		return selectedState(*raw, overrides.Context), nil
+	}
+
+	states := make([]*KubeState, 0, len(raw.Contexts))
+	for ctxName := range raw.Contexts {
+		states = append(states, stateFor(*raw, ctxName))
+	}
+
+	matches, err := rankContexts(env, states, raw.CurrentContext)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no kubeconfig context found matching environment: %s", env.Name)
+	}
+	if matches[0].Context == raw.CurrentContext {
+		return selectedState(*raw, raw.CurrentContext), nil
 	}
-	return "", fmt.Errorf("no kubeconfig context found matching regex: %s", regexStr)
+	return matches[0], nil
 }
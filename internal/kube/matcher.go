@@ -3,26 +3,321 @@ package kube
 import (
 	"fmt"
 	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
 
 	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
 )
 
-func FindMatchingEnv(currentContext string, cfg *config.Config) (*config.Environment, error) {
-	for _, env := range cfg.Environments {
-		// If context_match is empty, we skip (or treat as a fallback)
-		if env.ContextMatch == "" {
+// Specificity levels for a context_match pattern, from weakest to strongest.
+const (
+	MatchUnanchored   = 1 // e.g. ".*prod.*"
+	MatchHalfAnchored = 2 // e.g. "^prod" or "prod$"
+	MatchAnchored     = 3 // e.g. "^prod-cluster-[0-9]+$"
+	MatchExact        = 4 // pattern is a literal equal to the context name
+)
+
+// Candidate is an environment that matched a kube context, with the score used to rank it.
+type Candidate struct {
+	Env         *config.Environment
+	Context     string
+	Priority    int
+	Specificity int // of the most specific matcher that hit
+	Matchers    int // how many matchers hit
+	Literals    int // number of literal characters in the patterns, used as a tie-breaker
+}
+
+func (c Candidate) String() string {
+	return fmt.Sprintf("%s (%s, priority=%d, specificity=%d, matchers=%d)",
+		c.Env.Name, describeMatchers(c.Env), c.Priority, c.Specificity, c.Matchers)
+}
+
+// outranks reports whether c should be preferred over o.
+func (c Candidate) outranks(o Candidate) bool {
+	if c.Priority != o.Priority {
+		return c.Priority > o.Priority
+	}
+	return c.score().beats(o.score())
+}
+
+func (c Candidate) score() score {
+	return score{c.Specificity, c.Matchers, c.Literals}
+}
+
+func (c Candidate) ties(o Candidate) bool {
+	return !c.outranks(o) && !o.outranks(c)
+}
+
+// AmbiguousMatchError is returned when two or more environments match a context with the same score.
+type AmbiguousMatchError struct {
+	Context    string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "context %q matches multiple environments equally well:", e.Context)
+	for _, c := range e.Candidates {
+		fmt.Fprintf(&b, "\n  - %s", c)
+	}
+	b.WriteString("\nset 'priority' on one of them or make its context_match more specific")
+	return b.String()
+}
+
+// scorePattern checks pattern against s and returns its specificity and literal count.
+// ok is false when the pattern does not match.
+func scorePattern(pattern, s string) (specificity, literals int, ok bool, err error) {
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if !r.MatchString(s) {
+		return 0, 0, false, nil
+	}
+
+	if prefix, complete := r.LiteralPrefix(); complete && prefix == s {
+		return MatchExact, len(s), true, nil
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	literals = countLiterals(re)
+
+	begin, end := anchors(re)
+	switch {
+	case begin && end:
+		return MatchAnchored, literals, true, nil
+	case begin || end:
+		return MatchHalfAnchored, literals, true, nil
+	default:
+		return MatchUnanchored, literals, true, nil
+	}
+}
+
+func countLiterals(re *syntax.Regexp) int {
+	if re.Op == syntax.OpLiteral {
+		return len(re.Rune)
+	}
+	n := 0
+	for _, sub := range re.Sub {
+		n += countLiterals(sub)
+	}
+	return n
+}
+
+// anchors reports whether the parsed pattern is anchored at the start and at the end of the text.
+func anchors(re *syntax.Regexp) (begin, end bool) {
+	isBegin := func(r *syntax.Regexp) bool { return r.Op == syntax.OpBeginText || r.Op == syntax.OpBeginLine }
+	isEnd := func(r *syntax.Regexp) bool { return r.Op == syntax.OpEndText || r.Op == syntax.OpEndLine }
+
+	if re.Op == syntax.OpConcat && len(re.Sub) > 0 {
+		return isBegin(re.Sub[0]), isEnd(re.Sub[len(re.Sub)-1])
+	}
+	return isBegin(re), isEnd(re)
+}
+
+// matcher pairs a pattern from the config with the kubeconfig value it is checked against.
+type matcher struct {
+	field   string
+	pattern string
+	value   string
+}
+
+func matchersFor(env *config.Environment, state *KubeState) []matcher {
+	ms := []matcher{{"context_match", env.ContextMatch, state.Context}}
+	if m := env.Match; m != nil {
+		ms = append(ms,
+			matcher{"server", m.Server, state.Server},
+			matcher{"cluster", m.Cluster, state.Cluster},
+			matcher{"user", m.User, state.User},
+			matcher{"namespace", m.Namespace, state.Namespace},
+		)
+	}
+	return ms
+}
+
+func describeMatchers(env *config.Environment) string {
+	var parts []string
+	for _, m := range matchersFor(env, &KubeState{}) {
+		if m.pattern != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", m.field, m.pattern))
+		}
+	}
+	if env.Match != nil && env.Match.Mode == config.MatchModeAny {
+		parts = append(parts, "mode=any")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// score is how well an environment matches a context.
+type score struct {
+	specificity int // of the most specific matcher that hit
+	matchers    int // how many matchers hit
+	literals    int // summed over the matchers that hit
+}
+
+// beats compares the best single matcher first, so an exact context_match is never
+// outweighed by several loose ones; then the number of matchers, then the literals.
+func (s score) beats(o score) bool {
+	if s.specificity != o.specificity {
+		return s.specificity > o.specificity
+	}
+	if s.matchers != o.matchers {
+		return s.matchers > o.matchers
+	}
+	return s.literals > o.literals
+}
+
+// scoreEnv checks every matcher set on env against the state. In "all" mode each one
+// must match; in "any" mode one is enough.
+func scoreEnv(env *config.Environment, state *KubeState) (s score, ok bool, err error) {
+	mode := config.MatchModeAll
+	if env.Match != nil && env.Match.Mode != "" {
+		mode = env.Match.Mode
+	}
+	if mode != config.MatchModeAll && mode != config.MatchModeAny {
+		return score{}, false, fmt.Errorf("invalid match mode %q (use %q or %q)", mode, config.MatchModeAll, config.MatchModeAny)
+	}
+
+	for _, m := range matchersFor(env, state) {
+		if m.pattern == "" {
+			continue
+		}
+		spec, lits, hit, err := scorePattern(m.pattern, m.value)
+		if err != nil {
+			return score{}, false, fmt.Errorf("%s: %w", m.field, err)
+		}
+		if !hit {
+			if mode == config.MatchModeAll {
+				return score{}, false, nil
+			}
+			continue
+		}
+		s.specificity = max(s.specificity, spec)
+		s.matchers++
+		s.literals += lits
+		ok = true
+	}
+	return s, ok, nil
+}
+
+// CheckEnvironment verifies that every pattern set on env compiles and the match mode is valid.
+func CheckEnvironment(env *config.Environment) error {
+	if m := env.Match; m != nil && m.Mode != "" && m.Mode != config.MatchModeAll && m.Mode != config.MatchModeAny {
+		return fmt.Errorf("invalid match mode %q (use %q or %q)", m.Mode, config.MatchModeAll, config.MatchModeAny)
+	}
+	for _, m := range matchersFor(env, &KubeState{}) {
+		if m.pattern == "" {
 			continue
 		}
+		if _, err := regexp.Compile(m.pattern); err != nil {
+			return fmt.Errorf("%s: %w", m.field, err)
+		}
+	}
+	return nil
+}
+
+// HasMatchers reports whether env can be auto-detected at all.
+func HasMatchers(env *config.Environment) bool {
+	for _, m := range matchersFor(env, &KubeState{}) {
+		if m.pattern != "" {
+			return true
+		}
+	}
+	return false
+}
 
-		// Check if the current context matches the regex defined in YAML
-		match, err := regexp.MatchString(env.ContextMatch, currentContext)
+// RankEnvironments returns every environment matching the kube state, best candidate first.
+func RankEnvironments(state *KubeState, cfg *config.Config) ([]Candidate, error) {
+	var candidates []Candidate
+	for i := range cfg.Environments {
+		env := &cfg.Environments[i]
+
+		// Environments without any matcher are skipped (only reachable via -e / -I)
+		s, ok, err := scoreEnv(env, state)
 		if err != nil {
 			return nil, fmt.Errorf("invalid regex in config for %s: %w", env.Name, err)
 		}
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, Candidate{
+			Env:         env,
+			Context:     state.Context,
+			Priority:    env.Priority,
+			Specificity: s.specificity,
+			Matchers:    s.matchers,
+			Literals:    s.literals,
+		})
+	}
+
+	// Stable sort keeps file order among equals so the error lists them predictably
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].outranks(candidates[j])
+	})
+	return candidates, nil
+}
 
-		if match {
-			return &env, nil
+// FindMatchingEnv picks the best environment for the kube state. It fails with an
+// *AmbiguousMatchError when the top candidates tie.
+func FindMatchingEnv(state *KubeState, cfg *config.Config) (*config.Environment, error) {
+	candidates, err := RankEnvironments(state, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no matching environment found for context: %s", state.Context)
+	}
+
+	best := candidates[0]
+	tied := []Candidate{best}
+	for _, c := range candidates[1:] {
+		if c.ties(best) {
+			tied = append(tied, c)
 		}
 	}
-	return nil, fmt.Errorf("no matching environment found for context: %s", currentContext)
+	if len(tied) > 1 {
+		return nil, &AmbiguousMatchError{Context: state.Context, Candidates: tied}
+	}
+	return best.Env, nil
+}
+
+// rankContexts orders the contexts matching env, most specific first.
+// Ties are broken by preferring the current context, then alphabetically, so the
+// result is the same on every run.
+func rankContexts(env *config.Environment, states []*KubeState, current string) ([]*KubeState, error) {
+	type scored struct {
+		state *KubeState
+		score score
+	}
+	var matches []scored
+	for _, state := range states {
+		s, ok, err := scoreEnv(env, state)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex in config for %s: %w", env.Name, err)
+		}
+		if ok {
+			matches = append(matches, scored{state, s})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score.beats(b.score)
+		}
+		if (a.state.Context == current) != (b.state.Context == current) {
+			return a.state.Context == current
+		}
+		return a.state.Context < b.state.Context
+	})
+
+	ranked := make([]*KubeState, len(matches))
+	for i, m := range matches {
+		ranked[i] = m.state
+	}
+	return ranked, nil
 }
@@ -0,0 +1,249 @@
+package kube
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+)
+
+func TestScorePattern(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		specificity int
+		literals    int
+		ok          bool
+	}{
+		{"prod-cluster-01", MatchExact, 15, true},
+		{"^prod-cluster-[0-9]+$", MatchAnchored, 13, true},
+		{"^prod", MatchHalfAnchored, 4, true},
+		{"cluster-01$", MatchHalfAnchored, 10, true},
+		{".*prod.*", MatchUnanchored, 4, true},
+		{"prod", MatchUnanchored, 4, true},
+		{"^stage", 0, 0, false},
+	}
+	for _, tt := range tests {
+		spec, lits, ok, err := scorePattern(tt.pattern, "prod-cluster-01")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.pattern, err)
+		}
+		if spec != tt.specificity || lits != tt.literals || ok != tt.ok {
+			t.Errorf("%s: got (%d, %d, %v), want (%d, %d, %v)",
+				tt.pattern, spec, lits, ok, tt.specificity, tt.literals, tt.ok)
+		}
+	}
+
+	if _, _, _, err := scorePattern("prod-(", "prod-cluster-01"); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestRankEnvironments(t *testing.T) {
+	cfg := &config.Config{Environments: []config.Environment{
+		{Name: "loose", ContextMatch: "prod"},
+		{Name: "exact", ContextMatch: "prod-cluster-01"},
+		{Name: "anchored", ContextMatch: "^prod-cluster-[0-9]+$"},
+		{Name: "longer", ContextMatch: "prod-cluster"},
+		{Name: "other", ContextMatch: "^stage"},
+		{Name: "manual"}, // no matchers: only reachable with -e
+	}}
+	candidates, err := RankEnvironments(&KubeState{Context: "prod-cluster-01"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"exact", "anchored", "longer", "loose"}
+	if len(candidates) != len(want) {
+		t.Fatalf("got %d candidates, want %d: %v", len(candidates), len(want), candidates)
+	}
+	for i, name := range want {
+		if candidates[i].Env.Name != name {
+			t.Errorf("candidate %d: got %s, want %s", i, candidates[i].Env.Name, name)
+		}
+	}
+}
+
+func TestFindMatchingEnv(t *testing.T) {
+	state := &KubeState{Context: "prod-cluster-01"}
+	tests := []struct {
+		name string
+		envs []config.Environment
+		want string   // expected env, when not ambiguous
+		tied []string // expected candidates of the *AmbiguousMatchError
+	}{
+		{
+			name: "most specific wins",
+			envs: []config.Environment{
+				{Name: "a", ContextMatch: ".*prod.*"},
+				{Name: "b", ContextMatch: "^prod-cluster-01$"},
+			},
+			want: "b",
+		},
+		{
+			name: "priority beats specificity",
+			envs: []config.Environment{
+				{Name: "a", ContextMatch: "prod", Priority: 10},
+				{Name: "b", ContextMatch: "prod-cluster-01"},
+			},
+			want: "a",
+		},
+		{
+			name: "every tied candidate is reported",
+			envs: []config.Environment{
+				{Name: "a", ContextMatch: "prod"},
+				{Name: "b", ContextMatch: "clus"},
+				{Name: "c", ContextMatch: "^prod"},
+				{Name: "d", ContextMatch: "r-01$"},
+				{Name: "e", ContextMatch: "^zz"},
+			},
+			tied: []string{"c", "d"},
+		},
+		{
+			name: "same pattern twice",
+			envs: []config.Environment{
+				{Name: "a", ContextMatch: "prod"},
+				{Name: "b", ContextMatch: "prod"},
+			},
+			tied: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := FindMatchingEnv(state, &config.Config{Environments: tt.envs})
+			if tt.tied == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if env.Name != tt.want {
+					t.Errorf("got %s, want %s", env.Name, tt.want)
+				}
+				return
+			}
+
+			var ambiguous *AmbiguousMatchError
+			if !errors.As(err, &ambiguous) {
+				t.Fatalf("got %v, want an *AmbiguousMatchError", err)
+			}
+			if len(ambiguous.Candidates) != len(tt.tied) {
+				t.Fatalf("got candidates %v, want %v", ambiguous.Candidates, tt.tied)
+			}
+			for i, name := range tt.tied {
+				if ambiguous.Candidates[i].Env.Name != name {
+					t.Errorf("candidate %d: got %s, want %s", i, ambiguous.Candidates[i].Env.Name, name)
+				}
+			}
+		})
+	}
+
+	if _, err := FindMatchingEnv(state, &config.Config{Environments: []config.Environment{{Name: "a", ContextMatch: "^stage"}}}); err == nil {
+		t.Error("expected an error when nothing matches")
+	}
+}
+
+func TestMatchModes(t *testing.T) {
+	state := &KubeState{Context: "prod-cluster-01", Server: "https://prod.example.com", User: "admin"}
+	tests := []struct {
+		name string
+		env  config.Environment
+		ok   bool
+	}{
+		{"all: every matcher hits", config.Environment{ContextMatch: "prod", Match: &config.Match{Server: "prod", User: "^admin$"}}, true},
+		{"all: one matcher misses", config.Environment{ContextMatch: "prod", Match: &config.Match{Server: "stage"}}, false},
+		{"all is the default mode", config.Environment{ContextMatch: "stage", Match: &config.Match{Server: "prod"}}, false},
+		{"any: one matcher hits", config.Environment{ContextMatch: "stage", Match: &config.Match{Mode: config.MatchModeAny, Server: "prod"}}, true},
+		{"any: nothing hits", config.Environment{ContextMatch: "stage", Match: &config.Match{Mode: config.MatchModeAny, User: "viewer"}}, false},
+		{"match only, no context_match", config.Environment{Match: &config.Match{Server: "prod"}}, true},
+	}
+	for _, tt := range tests {
+		_, ok, err := scoreEnv(&tt.env, state)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ok != tt.ok {
+			t.Errorf("%s: got %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+
+	bad := config.Environment{ContextMatch: "prod", Match: &config.Match{Mode: "some"}}
+	if _, _, err := scoreEnv(&bad, state); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+}
+
+func TestBestMatcherBeforeMatcherCount(t *testing.T) {
+	state := &KubeState{Context: "prod-cluster-01", Server: "https://prod.example.com", Cluster: "gke-prod"}
+	tests := []struct {
+		name string
+		envs []config.Environment
+		want string
+	}{
+		{
+			name: "exact context beats two loose matchers",
+			envs: []config.Environment{
+				{Name: "loose", ContextMatch: "prod", Match: &config.Match{Server: "prod"}},
+				{Name: "exact", ContextMatch: "prod-cluster-01"},
+			},
+			want: "exact",
+		},
+		{
+			name: "half-anchored context beats two loose matchers",
+			envs: []config.Environment{
+				{Name: "loose", ContextMatch: "cluster", Match: &config.Match{Server: "example"}},
+				{Name: "anchored", ContextMatch: "^prod"},
+			},
+			want: "anchored",
+		},
+		{
+			name: "more matchers win at the same specificity",
+			envs: []config.Environment{
+				{Name: "one", ContextMatch: "^prod-cluster"},
+				{Name: "two", ContextMatch: "^prod", Match: &config.Match{Cluster: "^gke"}},
+			},
+			want: "two",
+		},
+	}
+	for _, tt := range tests {
+		env, err := FindMatchingEnv(state, &config.Config{Environments: tt.envs})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if env.Name != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, env.Name, tt.want)
+		}
+	}
+}
+
+func TestRankContextsDeterministic(t *testing.T) {
+	env := &config.Environment{Name: "prod", ContextMatch: "prod"}
+	states := []*KubeState{
+		{Context: "prod-c"}, {Context: "prod-a"}, {Context: "stage"}, {Context: "prod-b"}, {Context: "prod-a-01"},
+	}
+	// Equal scores: the current context first, then by name
+	want := "prod-b prod-a prod-a-01 prod-c"
+
+	for i := range states {
+		// Rotate the input to stand in for random map order
+		rotated := append(append([]*KubeState(nil), states[i:]...), states[:i]...)
+		ranked, err := rankContexts(env, rotated, "prod-b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range ranked {
+			got = append(got, s.Context)
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("rotation %d: got %v, want %s", i, got, want)
+		}
+	}
+
+	// A more specific match beats the current context
+	env.ContextMatch = "^prod-a"
+	ranked, err := rankContexts(env, states, "prod-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked) != 2 || ranked[0].Context != "prod-a" || ranked[1].Context != "prod-a-01" {
+		t.Errorf("got %v", ranked)
+	}
+}
@@ -0,0 +1,109 @@
+package kube
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	authv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Where a namespace list came from, best first.
+const (
+	SourceCluster    = "cluster"    // listing the namespaces
+	SourceRBAC       = "rbac"       // namespaces the user's permissions reach
+	SourceKubeconfig = "kubeconfig" // namespaces set on kubeconfig contexts
+	SourceGrafana    = "grafana"    // namespace label of the env's Prometheus, through Grafana
+	SourceConfig     = "config"     // the environment's namespaces list
+)
+
+// ClusterNamespaces lists the namespaces of state's cluster. Users who may not list
+// namespaces get the ones their RBAC rules let them into instead. The source says which.
+func ClusterNamespaces(state *KubeState) (namespaces []string, source string, err error) {
+	namespaces, err = GetNamespaces(state.Context)
+	if err == nil {
+		return namespaces, SourceCluster, nil
+	}
+	if !apierrors.IsForbidden(err) {
+		return nil, "", err
+	}
+	if accessible, rerr := accessibleNamespaces(state); rerr == nil && len(accessible) > 0 {
+		return accessible, SourceRBAC, nil
+	}
+	return nil, "", err // the forbidden error says more than the review's
+}
+
+// accessibleNamespaces asks the API server which namespaces the user can list pods in.
+// The candidates are the namespaces named by the user's rules on namespaces (a
+// SelfSubjectRulesReview) and those of the kubeconfig contexts of the same cluster, each
+// checked with a SelfSubjectAccessReview.
+func accessibleNamespaces(state *KubeState) ([]string, error) {
+	clientset, err := clientsetFor(state.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	candidates := map[string]bool{state.Namespace: true}
+	for _, ns := range KubeconfigNamespaces(state) {
+		candidates[ns] = true
+	}
+	rules, err := clientset.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &authv1.SelfSubjectRulesReview{
+		Spec: authv1.SelfSubjectRulesReviewSpec{Namespace: state.Namespace},
+	}, metav1.CreateOptions{})
+	if err == nil {
+		for _, rule := range rules.Status.ResourceRules {
+			if (slices.Contains(rule.Resources, "namespaces") || slices.Contains(rule.Resources, "*")) &&
+				(slices.Contains(rule.Verbs, "get") || slices.Contains(rule.Verbs, "*")) {
+				for _, name := range rule.ResourceNames {
+					candidates[name] = true
+				}
+			}
+		}
+	}
+
+	var namespaces []string
+	for ns := range candidates {
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authv1.ResourceAttributes{Namespace: ns, Verb: "list", Resource: "pods"},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if review.Status.Allowed {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// KubeconfigNamespaces returns the namespaces set on the kubeconfig contexts that point
+// at the same cluster as state, sorted.
+func KubeconfigNamespaces(state *KubeState) []string {
+	raw, err := loadRaw()
+	if err != nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var namespaces []string
+	for name, ctx := range raw.Contexts {
+		other := stateFor(*raw, name)
+		sameCluster := other.Cluster == state.Cluster || (state.Server != "" && other.Server == state.Server)
+		if ctx.Namespace == "" || !sameCluster || seen[ctx.Namespace] {
+			continue
+		}
+		seen[ctx.Namespace] = true
+		namespaces = append(namespaces, ctx.Namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
@@ -0,0 +1,148 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Workload kinds
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+)
+
+// Workload is a Deployment, StatefulSet or DaemonSet in a namespace.
+type Workload struct {
+	Kind     string
+	Name     string
+	Selector string // label selector matching its pods
+}
+
+// String returns "kind/name", the same form --workload accepts.
+func (w Workload) String() string {
+	return strings.ToLower(w.Kind) + "/" + w.Name
+}
+
+// ParseWorkloadRef splits "deployment/api", "sts/db" or just "api" into kind and name.
+// The kind is empty when not given.
+func ParseWorkloadRef(ref string) (kind, name string, err error) {
+	k, n, ok := strings.Cut(ref, "/")
+	if !ok {
+		return "", ref, nil
+	}
+	switch strings.ToLower(k) {
+	case "deployment", "deployments", "deploy":
+		return KindDeployment, n, nil
+	case "statefulset", "statefulsets", "sts":
+		return KindStatefulSet, n, nil
+	case "daemonset", "daemonsets", "ds":
+		return KindDaemonSet, n, nil
+	}
+	return "", "", fmt.Errorf("unknown workload kind %q (use deployment, statefulset or daemonset)", k)
+}
+
+// ListWorkloads returns the Deployments, StatefulSets and DaemonSets of a namespace,
+// sorted by name then kind.
+func ListWorkloads(contextName, namespace string) ([]Workload, error) {
+	clientset, err := clientsetFor(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	apps := clientset.AppsV1()
+	var workloads []Workload
+
+	deployments, err := apps.Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		workloads = append(workloads, Workload{KindDeployment, d.Name, selectorString(d.Spec.Selector)})
+	}
+
+	statefulSets, err := apps.StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, s := range statefulSets.Items {
+		workloads = append(workloads, Workload{KindStatefulSet, s.Name, selectorString(s.Spec.Selector)})
+	}
+
+	daemonSets, err := apps.DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, d := range daemonSets.Items {
+		workloads = append(workloads, Workload{KindDaemonSet, d.Name, selectorString(d.Spec.Selector)})
+	}
+
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Name != workloads[j].Name {
+			return workloads[i].Name < workloads[j].Name
+		}
+		return workloads[i].Kind < workloads[j].Kind
+	})
+	return workloads, nil
+}
+
+// FindWorkload looks up a workload by "kind/name" or bare name.
+func FindWorkload(contextName, namespace, ref string) (*Workload, error) {
+	kind, name, err := ParseWorkloadRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	workloads, err := ListWorkloads(contextName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i, w := range workloads {
+		if w.Name == name && (kind == "" || w.Kind == kind) {
+			return &workloads[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no workload %q in namespace %s", ref, namespace)
+}
+
+// ListPods returns the pod names of a namespace, limited to the workload's pods if given.
+func ListPods(contextName, namespace string, workload *Workload) ([]string, error) {
+	clientset, err := clientsetFor(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := metav1.ListOptions{}
+	if workload != nil {
+		opts.LabelSelector = workload.Selector
+	}
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var pods []string
+	for _, p := range podList.Items {
+		pods = append(pods, p.Name)
+	}
+	sort.Strings(pods)
+	return pods, nil
+}
+
+func selectorString(sel *metav1.LabelSelector) string {
+	s, err := metav1.LabelSelectorAsSelector(sel)
+	if err != nil {
+		return ""
+	}
+	return s.String()
+}
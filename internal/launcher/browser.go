@@ -0,0 +1,27 @@
+package launcher
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// BrowserCommand returns the command pkg/browser uses to open URLs on this OS,
+// and whether it is installed.
+func BrowserCommand() (string, bool) {
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{"open"}
+	case "windows":
+		return "rundll32", true
+	default:
+		candidates = []string{"xdg-open", "x-www-browser", "www-browser"}
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c); err == nil {
+			return c, true
+		}
+	}
+	return strings.Join(candidates, "/"), false
+}
@@ -0,0 +1,108 @@
+package launcher
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/timerange"
+)
+
+type exploreDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type exploreQuery struct {
+	RefID      string            `json:"refId"`
+	Expr       string            `json:"expr"`
+	Datasource exploreDatasource `json:"datasource"`
+}
+
+type exploreRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type explorePane struct {
+	Datasource string         `json:"datasource"`
+	Queries    []exploreQuery `json:"queries"`
+	Range      exploreRange   `json:"range"`
+}
+
+// buildExploreURL renders t.Explore into an Explore link. Grafana 10+ reads the
+// "panes" state; older versions only understand "left".
+func buildExploreURL(t Target) (string, error) {
+	q := *t.Explore
+
+	expr, err := render(q.Expr, t)
+	if err != nil {
+		return "", fmt.Errorf("query: %w", err)
+	}
+
+	dsType := q.DatasourceType()
+	var uid string
+	switch dsType {
+	case config.QueryPrometheus:
+		uid = t.Env.PrometheusUID
+	case config.QueryLoki:
+		uid = t.Env.LokiUID
+	default:
+		return "", fmt.Errorf("unsupported query datasource %q (use prometheus or loki)", dsType)
+	}
+	if uid == "" {
+		return "", fmt.Errorf("%s has no %s datasource UID configured", t.Env.Name, dsType)
+	}
+
+	var r exploreRange
+	for _, p := range []struct {
+		key  string
+		dst  *string
+		vals []string
+	}{
+		{"from", &r.From, []string{t.From, t.Env.From, "now-1h"}},
+		{"to", &r.To, []string{t.To, t.Env.To, "now"}},
+	} {
+		v, err := timerange.ParseTime(cmp.Or(p.vals...))
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", p.key, err)
+		}
+		*p.dst = v
+	}
+
+	pane := explorePane{
+		Datasource: uid,
+		Queries: []exploreQuery{{
+			RefID:      "A",
+			Expr:       expr,
+			Datasource: exploreDatasource{Type: dsType, UID: uid},
+		}},
+		Range: r,
+	}
+
+	params := url.Values{}
+	if orgID := orgIDFor(t); orgID != 0 {
+		params.Add("orgId", strconv.Itoa(orgID))
+	}
+
+	if t.Env.ExploreFormat == "left" {
+		state, err := json.Marshal(pane)
+		if err != nil {
+			return "", err
+		}
+		params.Add("left", string(state))
+	} else {
+		state, err := json.Marshal(map[string]explorePane{"gc": pane})
+		if err != nil {
+			return "", err
+		}
+		params.Add("schemaVersion", "1")
+		params.Add("panes", string(state))
+	}
+
+	return fmt.Sprintf("%s/explore?%s", strings.TrimSuffix(t.Env.BaseURL, "/"), params.Encode()), nil
+}
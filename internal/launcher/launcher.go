@@ -1,52 +1,234 @@
 package launcher
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
 
+	"github.com/PraveenPrabhuT/grafana-connect/internal/clipboard"
 	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
-	"github.com/atotto/clipboard"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/secret"
+	"github.com/PraveenPrabhuT/grafana-connect/internal/timerange"
 	"github.com/pkg/browser"
 )
 
-// Open now only needs the Environment and the Namespace
-func Open(env config.Environment, namespace string) {
+// Target is everything a launch needs. It is also the data passed to variable templates.
+// Env is expected to be the effective environment (config.Config.Effective).
+type Target struct {
+	Env        config.Environment
+	Dashboard  config.Dashboard
+	Namespace  string
+	Context    string // kube context, empty if none was found
+	Cluster    string // kubeconfig cluster name
+	Deployment string // workload name, whatever its kind
+	Pod        string
+	// WorkloadKind is Deployment, StatefulSet or DaemonSet when a workload was chosen
+	WorkloadKind string
+	// From/To override the configured time range (see timerange.ParseTime for the syntax)
+	From string
+	To   string
+	// Explore, when set, opens this query in Grafana Explore instead of the dashboard
+	Explore *config.Query
+}
+
+// Open builds the URL, copies the password and opens the browser. It returns an error
+// if the URL can't be built; a browser that fails to start only prints the link.
+func Open(t Target) error {
 	// 1. Build URL (Using env-specific fields)
-	params := url.Values{}
-	params.Add("orgId", "1")
-	params.Add("refresh", "30s")
-	params.Add("var-DS_PROMETHEUS", env.PrometheusUID)
-	params.Add("var-namespace", namespace)
-	params.Add("var-deployment", "All")
-	params.Add("var-pod", "All")
-
-	// Safety check for dashboard path
-	dashPath := env.Dashboard
-	if dashPath == "" {
-		dashPath = "k8s-pod-resources/kubernetes-pod-resource-dashboard" // Hard fallback just in case
-	}
-
-	finalURL := fmt.Sprintf("%s/d/%s?%s",
-		env.BaseURL,
-		dashPath,
-		params.Encode(),
-	)
+	finalURL, err := BuildURL(t)
+	if err != nil {
+		return err
+	}
 
 	// 2. Handle Clipboard
-	if env.Password != "" {
-		// Init returns an error if the system clipboard is missing (e.g. headless linux)
-		if err := clipboard.WriteAll(env.Password); err == nil {
-			fmt.Println("📋 Password copied to clipboard!")
-		} else {
-			// On Linux, this might fail if xclip/xsel isn't installed. Warn the user.
-			fmt.Printf("⚠️  Clipboard error: %v (Do you have xclip/xsel installed?)\n", err)
-		}
-	}
+	CopyPassword(t.Env)
 
 	// 3. Launch
-	fmt.Printf("🚀 Opening %s [%s]...\n", env.Name, namespace)
+	Browse(t, finalURL)
+	return nil
+}
+
+// CopyPassword puts the env's password (if any) on the clipboard, reporting what happened.
+func CopyPassword(env config.Environment) {
+	password, err := secret.Password(env)
+	if err != nil {
+		fmt.Printf("⚠️  Could not resolve password: %v\n", err)
+	}
+	if password == "" {
+		return
+	}
+	err = clipboard.WriteSecret(password)
+	switch {
+	case err == nil:
+		fmt.Printf("📋 Password copied to clipboard! (%s)\n", clipboard.Current().Name())
+	case errors.Is(err, clipboard.ErrNotCleared):
+		fmt.Printf("📋 Password copied to clipboard! (%s)\n", clipboard.Current().Name())
+		fmt.Printf("⚠️  %v\n", err)
+	case errors.Is(err, clipboard.ErrDisabled):
+		// clipboard: none
+	default:
+		// e.g. xclip/xsel missing, or no terminal for OSC 52. Warn the user.
+		fmt.Printf("⚠️  Clipboard error: %v (set 'clipboard' in config to pick a backend)\n", err)
+	}
+}
+
+// CopyURL puts the dashboard link itself on the clipboard.
+func CopyURL(finalURL string) error {
+	return clipboard.Write(finalURL)
+}
+
+// Browse opens the URL, printing it instead if no browser is available.
+func Browse(t Target, finalURL string) {
+	fmt.Printf("🚀 Opening %s [%s]...\n", t.Env.Name, t.Namespace)
 	if err := browser.OpenURL(finalURL); err != nil {
 		fmt.Printf("❌ Failed to open browser: %v\n", err)
 		fmt.Printf("   Link: %s\n", finalURL)
 	}
 }
+
+// Summary is the machine-readable description of a launch (-o json).
+type Summary struct {
+	Env       string `json:"env"`
+	Namespace string `json:"namespace"`
+	Context   string `json:"context,omitempty"`
+	Workload  string `json:"workload,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Dashboard string `json:"dashboard"`
+	URL       string `json:"url"`
+	ShortURL  string `json:"short_url,omitempty"`
+}
+
+// Summarize describes the target and its URL.
+func Summarize(t Target, finalURL string) Summary {
+	return Summary{
+		Env:       t.Env.Name,
+		Namespace: t.Namespace,
+		Context:   t.Context,
+		Workload:  t.Deployment,
+		Pod:       t.Pod,
+		Dashboard: dashboardPath(t),
+		URL:       finalURL,
+	}
+}
+
+// BuildURL renders the dashboard (or Explore) URL for the target without any side effects.
+func BuildURL(t Target) (string, error) {
+	if t.Explore != nil {
+		return buildExploreURL(t)
+	}
+	if t.Deployment == "" {
+		t.Deployment = "All"
+	}
+	if t.Pod == "" {
+		t.Pod = "All"
+	}
+
+	path := dashboardPath(t)
+	if path == "" {
+		return "", fmt.Errorf("no dashboard configured for %s", t.Env.Name)
+	}
+
+	params := url.Values{}
+	if orgID := orgIDFor(t); orgID != 0 {
+		params.Add("orgId", strconv.Itoa(orgID))
+	}
+	if refresh := cmp.Or(t.Dashboard.Refresh, t.Env.Refresh); refresh != "" {
+		params.Add("refresh", refresh)
+	}
+
+	// The dashboard's variables, else the env's (which default to the built-in ones)
+	vars := t.Dashboard.Vars
+	if vars == nil {
+		vars = t.Env.Vars
+	}
+	if err := addVars(params, vars, t); err != nil {
+		return "", err
+	}
+	if err := addTimeRange(params, t); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/d/%s?%s",
+		strings.TrimSuffix(t.Env.BaseURL, "/"),
+		path,
+		params.Encode(),
+	), nil
+}
+
+func dashboardPath(t Target) string {
+	if t.Explore != nil {
+		return "explore"
+	}
+	if t.Dashboard.Path != "" {
+		return t.Dashboard.Path
+	}
+	return t.Env.Dashboard
+}
+
+// orgIDFor takes the org from the dashboard, then the env. 0 leaves it to Grafana.
+func orgIDFor(t Target) int {
+	if t.Dashboard.OrgID != 0 {
+		return t.Dashboard.OrgID
+	}
+	return t.Env.OrgID
+}
+
+// addVars renders each variable template and adds the non-empty results as var-<name>.
+func addVars(params url.Values, vars map[string]config.VarValues, t Target) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, raw := range vars[name] {
+			value, err := render(raw, t)
+			if err != nil {
+				return fmt.Errorf("dashboard variable %s: %w", name, err)
+			}
+			if value != "" {
+				params.Add("var-"+name, value)
+			}
+		}
+	}
+	return nil
+}
+
+// addTimeRange adds from/to, taking each from the target, then the dashboard, then the env.
+func addTimeRange(params url.Values, t Target) error {
+	for _, p := range []struct{ key, value string }{
+		{"from", cmp.Or(t.From, t.Dashboard.From, t.Env.From)},
+		{"to", cmp.Or(t.To, t.Dashboard.To, t.Env.To)},
+	} {
+		if p.value == "" {
+			continue
+		}
+		v, err := timerange.ParseTime(p.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.key, err)
+		}
+		params.Add(p.key, v)
+	}
+	return nil
+}
+
+func render(text string, t Target) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("var").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, t); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
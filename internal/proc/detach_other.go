@@ -0,0 +1,8 @@
+//go:build !unix
+
+package proc
+
+import "os/exec"
+
+// Detach is a no-op where sessions don't exist.
+func Detach(cmd *exec.Cmd) {}
@@ -0,0 +1,94 @@
+package secret
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// KeyringService is the default service name for entries written by grafana-connect.
+const KeyringService = "grafana-connect"
+
+// splitKeyringKey accepts "service/account" or just "account".
+func splitKeyringKey(key string) (service, account string) {
+	if s, a, ok := strings.Cut(key, "/"); ok {
+		return s, a
+	}
+	return KeyringService, key
+}
+
+// KeyringRef builds the password_ref for an account stored by StoreKeyring.
+func KeyringRef(account string) string {
+	return "keyring:" + KeyringService + "/" + account
+}
+
+// lookupKeyring reads from the macOS Keychain or the Secret Service (GNOME Keyring, KWallet) on Linux.
+func lookupKeyring(key string) (string, error) {
+	service, account := splitKeyringKey(key)
+
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux":
+		c = exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", fmt.Errorf("no keyring support on %s", runtime.GOOS)
+	}
+
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("no entry for %s/%s (%v)", service, account, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// StoreKeyring saves the secret in the OS keyring under KeyringService, replacing any previous value.
+func StoreKeyring(account, secret string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// add-generic-password only takes the password as an argument, which ps would
+		// show. In interactive mode (-i) the command line is read from stdin instead.
+		if strings.ContainsAny(secret, "\r\n") {
+			return fmt.Errorf("keyring store failed: the secret contains a line break")
+		}
+		c = exec.Command("security", "-i")
+		c.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			securityQuote(KeyringService), securityQuote(account), securityQuote(secret)))
+	case "linux":
+		c = exec.Command("secret-tool", "store", "--label=grafana-connect: "+account,
+			"service", KeyringService, "account", account)
+		c.Stdin = strings.NewReader(secret)
+	default:
+		return fmt.Errorf("no keyring support on %s", runtime.GOOS)
+	}
+
+	out, err := c.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("keyring store failed: %v %s", err, strings.TrimSpace(string(out)))
+	}
+	// security -i exits 0 even when its command fails, so read the value back
+	if runtime.GOOS == "darwin" {
+		if got, err := lookupKeyring(account); err != nil || got != secret {
+			return fmt.Errorf("keyring store failed: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// securityQuote quotes an argument for the command line of `security -i`.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// KeyringAvailable reports whether the keyring CLI for this OS is installed.
+func KeyringAvailable() bool {
+	tool := map[string]string{"darwin": "security", "linux": "secret-tool"}[runtime.GOOS]
+	if tool == "" {
+		return false
+	}
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
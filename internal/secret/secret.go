@@ -0,0 +1,105 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+)
+
+// Backend resolves the part of a password_ref after the "scheme:" prefix.
+type Backend interface {
+	Resolve(key string) (string, error)
+}
+
+// BackendFunc adapts a plain function to the Backend interface.
+type BackendFunc func(key string) (string, error)
+
+func (f BackendFunc) Resolve(key string) (string, error) { return f(key) }
+
+var backends = map[string]Backend{
+	"keyring": BackendFunc(lookupKeyring),
+	"exec":    BackendFunc(runExec),
+	"env":     BackendFunc(readEnv),
+	"file":    BackendFunc(readFile),
+}
+
+// Register adds or replaces the backend used for refs starting with "scheme:".
+func Register(scheme string, b Backend) {
+	backends[scheme] = b
+}
+
+// Resolve turns a ref such as "env:GRAFANA_PASS" or "exec:pass show grafana/prod" into the secret.
+func Resolve(ref string) (string, error) {
+	scheme, key, ok := strings.Cut(ref, ":")
+	if !ok || key == "" {
+		return "", fmt.Errorf("invalid password_ref %q (expected <scheme>:<value>)", ref)
+	}
+	b, ok := backends[scheme]
+	if !ok {
+		return "", fmt.Errorf("unknown password_ref scheme %q", scheme)
+	}
+	secret, err := b.Resolve(key)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", scheme, err)
+	}
+	return secret, nil
+}
+
+// Password returns the env's password. PasswordRef takes precedence over the plaintext field.
+func Password(env config.Environment) (string, error) {
+	if env.PasswordRef == "" {
+		return env.Password, nil
+	}
+	return Resolve(env.PasswordRef)
+}
+
+// Token returns the env's Grafana API token, if any. TokenRef takes precedence over Token.
+func Token(env config.Environment) (string, error) {
+	if env.TokenRef == "" {
+		return env.Token, nil
+	}
+	return Resolve(env.TokenRef)
+}
+
+func runExec(command string) (string, error) {
+	// Password managers may prompt for a PIN or touch, so be generous
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "sh", "-c", command)
+	c.Stdin = os.Stdin
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("command %q failed: %w", command, err)
+	}
+	// Only the first line is the secret (pass(1) convention)
+	line, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimRight(line, "\r"), nil
+}
+
+func readEnv(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
+
+func readFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, rest)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
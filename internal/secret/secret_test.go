@@ -0,0 +1,106 @@
+package secret
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/config"
+)
+
+func TestResolve(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, "grafana-prod"), []byte("from-home\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	abs := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(abs, []byte("from-file\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GRAFANA_TEST_PASS", "from-env")
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"env:GRAFANA_TEST_PASS", "from-env"},
+		{"file:" + abs, "from-file"},
+		{"file:~/grafana-prod", "from-home"},
+		{"exec:printf 'first\\nsecond\\n'", "first"}, // pass(1): only the first line
+		{"exec:printf 'crlf\\r\\n'", "crlf"},
+		{"exec:echo 'a:b'", "a:b"}, // only the first colon separates the scheme
+	}
+	for _, tt := range tests {
+		got, err := Resolve(tt.ref)
+		if err != nil {
+			t.Errorf("%s: %v", tt.ref, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Setenv("GRAFANA_TEST_EMPTY", "")
+	tests := []struct {
+		ref  string
+		want string // part of the error message
+	}{
+		{"GRAFANA_TEST_PASS", "invalid password_ref"},
+		{"env:", "invalid password_ref"},
+		{"vault:secret/grafana", `unknown password_ref scheme "vault"`},
+		{"env:GRAFANA_TEST_UNSET_VARIABLE", "env: environment variable GRAFANA_TEST_UNSET_VARIABLE is not set"},
+		{"env:GRAFANA_TEST_EMPTY", "is not set"},
+		{"file:" + filepath.Join(t.TempDir(), "missing"), "file:"},
+		{"exec:exit 3", "exec: command \"exit 3\" failed"},
+	}
+	for _, tt := range tests {
+		_, err := Resolve(tt.ref)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.ref, err, tt.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test", BackendFunc(func(key string) (string, error) {
+		if key == "bad" {
+			return "", errors.New("no such secret")
+		}
+		return "secret-" + key, nil
+	}))
+	t.Cleanup(func() { delete(backends, "test") })
+
+	if got, err := Resolve("test:prod"); err != nil || got != "secret-prod" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if _, err := Resolve("test:bad"); err == nil || err.Error() != "test: no such secret" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestPasswordAndToken(t *testing.T) {
+	t.Setenv("GRAFANA_TEST_PASS", "from-ref")
+
+	env := config.Environment{Password: "plain", Token: "plain-token"}
+	if got, _ := Password(env); got != "plain" {
+		t.Errorf("Password without ref: got %q", got)
+	}
+	if got, _ := Token(env); got != "plain-token" {
+		t.Errorf("Token without ref: got %q", got)
+	}
+
+	// The refs take precedence over the plaintext fields
+	env.PasswordRef, env.TokenRef = "env:GRAFANA_TEST_PASS", "env:GRAFANA_TEST_PASS"
+	if got, _ := Password(env); got != "from-ref" {
+		t.Errorf("Password with ref: got %q", got)
+	}
+	if got, _ := Token(env); got != "from-ref" {
+		t.Errorf("Token with ref: got %q", got)
+	}
+}
@@ -0,0 +1,112 @@
+package timerange
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Range is a Grafana time range. Values are either relative ("now-6h") or unix milliseconds.
+type Range struct {
+	From string
+	To   string
+}
+
+var (
+	// now, now-6h, now-1d/d, now/w, now-1h-30m ...
+	relativeRe = regexp.MustCompile(`^now([+-]\d+(ms|s|m|h|d|w|M|y))*(/(s|m|h|d|w|M|y))?$`)
+	// A bare Grafana duration such as 30m, 2d or 1w
+	grafanaDurationRe = regexp.MustCompile(`^\d+(s|m|h|d|w|M|y)$`)
+	unixMsRe          = regexp.MustCompile(`^\d{12,}$`)
+	daysRe            = regexp.MustCompile(`^(\d+)(d|w)$`)
+)
+
+// ParseTime validates a --from/--to style value and returns it in a form Grafana accepts:
+// relative values are kept, RFC3339 timestamps become unix ms, and unix ms pass through.
+func ParseTime(s string) (string, error) {
+	switch {
+	case relativeRe.MatchString(s):
+		return s, nil
+	case unixMsRe.MatchString(s):
+		return s, nil
+	}
+	t, err := parseAbsolute(s)
+	if err != nil {
+		return "", err
+	}
+	return msString(t), nil
+}
+
+// Last returns the range ending now and covering d (e.g. "2h", "7d", "1h30m").
+func Last(d string) (Range, error) {
+	if grafanaDurationRe.MatchString(d) {
+		if n, _ := strconv.Atoi(d[:len(d)-1]); n <= 0 {
+			return Range{}, notPositive(d)
+		}
+		return Range{From: "now-" + d, To: "now"}, nil
+	}
+	dur, err := ParseDuration(d)
+	if err != nil {
+		return Range{}, err
+	}
+	return Range{From: fmt.Sprintf("now-%ds", int64(dur.Seconds())), To: "now"}, nil
+}
+
+// Around returns a window of the given width centered on an absolute timestamp.
+func Around(at, window string) (Range, error) {
+	t, err := parseAbsolute(at)
+	if err != nil {
+		return Range{}, err
+	}
+	w, err := ParseDuration(window)
+	if err != nil {
+		return Range{}, err
+	}
+	return Range{From: msString(t.Add(-w / 2)), To: msString(t.Add(w / 2))}, nil
+}
+
+// ParseDuration is time.ParseDuration plus the d (day) and w (week) units. Only
+// positive durations are accepted.
+func ParseDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	if m := daysRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		d = time.Duration(n) * 24 * time.Hour
+		if m[2] == "w" {
+			d *= 7
+		}
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid duration %q (e.g. 30m, 2h, 1d)", s)
+		}
+	}
+	if d <= 0 {
+		return 0, notPositive(s)
+	}
+	return d, nil
+}
+
+func notPositive(s string) error {
+	return fmt.Errorf("duration must be positive: %q", s)
+}
+
+// parseAbsolute accepts RFC3339 (with or without seconds' fraction) and unix ms.
+func parseAbsolute(s string) (time.Time, error) {
+	if unixMsRe.MatchString(s) {
+		ms, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(ms), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use now-6h, RFC3339 like 2024-05-01T10:00:00Z, or unix ms)", s)
+}
+
+func msString(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
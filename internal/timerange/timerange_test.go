@@ -0,0 +1,122 @@
+package timerange
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // "" for an error
+	}{
+		{"now", "now"},
+		{"now-6h", "now-6h"},
+		{"now-1d/d", "now-1d/d"},
+		{"now-1h-30m", "now-1h-30m"},
+		{"now/w", "now/w"},
+		{"1714557600000", "1714557600000"},
+		{"2024-05-01T10:00:00Z", "1714557600000"},
+		{"2024-05-01T12:00:00+02:00", "1714557600000"},
+		{"2024-05-01T10:00:00.250Z", "1714557600250"},
+		{"now-6x", ""},
+		{"yesterday", ""},
+		{"2024-05-01", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: got %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration // 0 for an error
+	}{
+		{"30m", 30 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"2d", 48 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+		{"0d", 0},
+		{"0w", 0},
+		{"0s", 0},
+		{"-1h", 0},
+		{"2days", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if tt.want == 0 {
+			if err == nil {
+				t.Errorf("%q: got %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestLast(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // From; "" for an error
+	}{
+		{"2h", "now-2h"},
+		{"7d", "now-7d"},
+		{"1M", "now-1M"},
+		{"1h30m", "now-5400s"},
+		{"0d", ""},
+		{"0h", ""},
+		{"0s", ""},
+		{"soon", ""},
+	}
+	for _, tt := range tests {
+		r, err := Last(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: got %+v, want an error", tt.in, r)
+			}
+			continue
+		}
+		if err != nil || r.From != tt.want || r.To != "now" {
+			t.Errorf("%s: got %+v, %v, want From %q", tt.in, r, err, tt.want)
+		}
+	}
+}
+
+func TestAround(t *testing.T) {
+	tests := []struct {
+		at, window string
+		from, to   string // "" for an error
+	}{
+		{"2024-05-01T10:00:00Z", "30m", "1714556700000", "1714558500000"},
+		{"1714557600000", "1d", "1714514400000", "1714600800000"},
+		{"2024-05-01T10:00:00Z", "0d", "", ""},
+		{"2024-05-01T10:00:00Z", "0s", "", ""},
+		{"now-1h", "30m", "", ""}, // --at needs an absolute time
+		{"2024-05-01T10:00:00Z", "wide", "", ""},
+	}
+	for _, tt := range tests {
+		r, err := Around(tt.at, tt.window)
+		if tt.from == "" {
+			if err == nil {
+				t.Errorf("%s ±%s: got %+v, want an error", tt.at, tt.window, r)
+			}
+			continue
+		}
+		if err != nil || r.From != tt.from || r.To != tt.to {
+			t.Errorf("%s ±%s: got %+v, %v, want %s..%s", tt.at, tt.window, r, err, tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/grafana"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+func SelectDashboard(hits []grafana.SearchHit) (*grafana.SearchHit, error) {
+	idx, err := fuzzyfinder.Find(
+		hits,
+		func(i int) string {
+			return hits[i].Folder() + " / " + hits[i].Title
+		},
+		fuzzyfinder.WithPromptString("Dashboard > "),
+		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
+			if i == -1 {
+				return ""
+			}
+			hit := hits[i]
+			tags := "-"
+			if len(hit.Tags) > 0 {
+				tags = strings.Join(hit.Tags, ", ")
+			}
+			return fmt.Sprintf("Dashboard: %s\nFolder: %s\nTags: %s\nPath: %s",
+				hit.Title, hit.Folder(), tags, hit.Path())
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &hits[idx], nil
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/grafana"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+func SelectDatasource(label string, sources []grafana.Datasource) (*grafana.Datasource, error) {
+	idx, err := fuzzyfinder.Find(
+		sources,
+		func(i int) string {
+			return sources[i].Name
+		},
+		fuzzyfinder.WithPromptString(label+" > "),
+		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
+			if i == -1 {
+				return ""
+			}
+			ds := sources[i]
+			return fmt.Sprintf("Datasource: %s\nUID: %s\nType: %s\nURL: %s\nDefault: %t",
+				ds.Name, ds.UID, ds.Type, ds.URL, ds.IsDefault)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sources[idx], nil
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/PraveenPrabhuT/grafana-connect/internal/kube"
+	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/manifoldco/promptui"
+)
+
+// namespaceHeaders tell where the picked-from list came from.
+var namespaceHeaders = map[string]string{
+	kube.SourceCluster:    "Namespaces of the cluster",
+	kube.SourceRBAC:       "Namespaces you have access to (listing namespaces is forbidden)",
+	kube.SourceKubeconfig: "Namespaces of your kubeconfig contexts (listing namespaces failed)",
+	kube.SourceGrafana:    "Namespaces seen by Prometheus, through Grafana",
+	kube.SourceConfig:     "Namespaces from the config",
+}
+
+// SelectNamespace is SelectString for namespaces, with the list's source (a kube.Source*
+// constant) in the header.
+func SelectNamespace(namespaces []string, source string) (string, error) {
+	header, ok := namespaceHeaders[source]
+	if !ok {
+		header = namespaceHeaders[kube.SourceCluster]
+	}
+	idx, err := fuzzyfinder.Find(
+		namespaces,
+		func(i int) string {
+			return namespaces[i]
+		},
+		fuzzyfinder.WithPromptString("Select Namespace > "),
+		fuzzyfinder.WithHeader(header),
+	)
+	if err != nil {
+		return "", err
+	}
+	return namespaces[idx], nil
+}
+
+// PromptNamespace asks for a namespace name, for when none could be listed. The prompt
+// goes to stderr so that --print and -o json keep stdout for the result.
+func PromptNamespace(def string) (string, error) {
+	prompt := promptui.Prompt{Label: "Namespace", Default: def, Stdout: os.Stderr}
+	return prompt.Run()
+}